@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package annotation validates the ingress.citrix.com annotations of Ingress resources against a catalog
+// of the annotations supported by the ingress controller
+package annotation
+
+// Prefix of the annotations read by the ingress controller
+const Prefix = "ingress.citrix.com/"
+
+// Types of annotation values
+const (
+	TypeString = "string"
+	TypeIP     = "ip"
+	TypePort   = "port"
+	TypeEnum   = "enum"
+	TypeJSON   = "json"
+)
+
+// Layouts of JSON annotation values
+const (
+	// LayoutEntity is an object of NetScaler entity attributes, {"attribute": "value"}
+	LayoutEntity = "entity"
+	// LayoutServiceEntity is an object of entity attributes per backend service, {"service": {"attribute": "value"}}
+	LayoutServiceEntity = "service-entity"
+	// LayoutServiceValue is a value per backend service, {"service": "value"}
+	LayoutServiceValue = "service-value"
+	// LayoutEntityMap is an object of entity attributes per key, the keys are given by Values
+	LayoutEntityMap = "entity-map"
+)
+
+// Spec describes a supported annotation and the values it accepts
+type Spec struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Values are the allowed values of an enum, of the per service values or the keys of an entity map
+	Values []string `json:"values,omitempty"`
+	Layout string   `json:"layout,omitempty"`
+	// Deprecated is the replacement of a deprecated annotation
+	Deprecated string `json:"deprecated,omitempty"`
+}
+
+// catalog lists the annotations understood by the ingress controller
+var catalog = []Spec{
+	{Name: Prefix + "frontend-ip", Type: TypeIP},
+	{Name: Prefix + "insecure-port", Type: TypePort},
+	{Name: Prefix + "secure-port", Type: TypePort},
+	{Name: Prefix + "insecure-termination", Type: TypeEnum, Values: []string{"allow", "redirect", "disallow"}},
+	{Name: Prefix + "insecure-service-type", Type: TypeEnum, Values: []string{"http", "tcp", "udp", "sip_udp", "any"}},
+	{Name: Prefix + "secure-service-type", Type: TypeEnum, Values: []string{"ssl", "ssl_tcp", "ssl_bridge", "dtls"}},
+	{Name: Prefix + "path-match-method", Type: TypeEnum, Values: []string{"prefix", "exact"}},
+	{Name: Prefix + "deployment", Type: TypeEnum, Values: []string{"dsr"}},
+	{Name: Prefix + "preconfigured-certkey", Type: TypeString},
+	{Name: Prefix + "ca-secret", Type: TypeString},
+	{Name: Prefix + "ipam-range", Type: TypeString},
+	{Name: Prefix + "secure_backend", Type: TypeJSON, Layout: LayoutServiceValue, Values: []string{"True", "False"}},
+	{Name: Prefix + "backend-secret", Type: TypeJSON, Layout: LayoutServiceValue},
+	{Name: Prefix + "backend-ca-secret", Type: TypeJSON, Layout: LayoutServiceValue},
+	{Name: Prefix + "frontend-httpprofile", Type: TypeJSON, Layout: LayoutEntity},
+	{Name: Prefix + "frontend-tcpprofile", Type: TypeJSON, Layout: LayoutEntity},
+	{Name: Prefix + "frontend-sslprofile", Type: TypeJSON, Layout: LayoutEntity},
+	{Name: Prefix + "backend-httpprofile", Type: TypeJSON, Layout: LayoutServiceEntity},
+	{Name: Prefix + "backend-tcpprofile", Type: TypeJSON, Layout: LayoutServiceEntity},
+	{Name: Prefix + "backend-sslprofile", Type: TypeJSON, Layout: LayoutServiceEntity},
+	{Name: Prefix + "csvserver", Type: TypeJSON, Layout: LayoutEntity},
+	{Name: Prefix + "lbvserver", Type: TypeJSON, Layout: LayoutServiceEntity},
+	{Name: Prefix + "servicegroup", Type: TypeJSON, Layout: LayoutServiceEntity},
+	{Name: Prefix + "monitor", Type: TypeJSON, Layout: LayoutServiceEntity},
+	{Name: Prefix + "analyticsprofile", Type: TypeJSON, Layout: LayoutEntityMap, Values: []string{"webinsight", "tcpinsight"}},
+	{Name: "kubernetes.io/ingress.class", Type: TypeString, Deprecated: "spec.ingressClassName"},
+}
+
+// lookup returns the spec of an annotation, nil when it is not in the catalog
+func lookup(name string) *Spec {
+	for i := range catalog {
+		if catalog[i].Name == name {
+			return &catalog[i]
+		}
+	}
+	return nil
+}
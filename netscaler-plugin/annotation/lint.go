@@ -0,0 +1,265 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package annotation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// Severities of findings
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Finding is a problem found in an annotation of an Ingress
+type Finding struct {
+	Source     string `json:"source"`
+	Line       int    `json:"line,omitempty"`
+	Namespace  string `json:"namespace"`
+	Ingress    string `json:"ingress"`
+	Annotation string `json:"annotation"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+}
+
+// Ingress is an Ingress to lint with its backend services. Lines gives the line of each annotation when
+// the Ingress was read from a manifest.
+type Ingress struct {
+	Source      string
+	Namespace   string
+	Name        string
+	Annotations map[string]string
+	Services    []string
+	Lines       map[string]int
+	// Unquoted lists the annotations whose manifest value is not a YAML string, which the API server rejects
+	Unquoted []string
+}
+
+// Lint checks the annotations of an Ingress against the catalog, in the order of the manifest
+func Lint(ing Ingress) []Finding {
+	names := make([]string, 0, len(ing.Annotations))
+	for name := range ing.Annotations {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if ing.Lines[names[i]] != ing.Lines[names[j]] {
+			return ing.Lines[names[i]] < ing.Lines[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	findings := make([]Finding, 0)
+	for _, name := range names {
+		report := func(severity string, message string) {
+			findings = append(findings, Finding{Source: ing.Source, Line: ing.Lines[name], Namespace: ing.Namespace, Ingress: ing.Name,
+				Annotation: name, Severity: severity, Message: message})
+		}
+		if util.ContainsString(ing.Unquoted, name) {
+			report(SeverityError, "value must be a quoted string")
+			continue
+		}
+		spec := lookup(name)
+		if spec == nil {
+			if message, ok := unknown(name); ok {
+				report(SeverityError, message)
+			}
+			continue
+		}
+		if spec.Deprecated != "" {
+			report(SeverityWarning, "deprecated, use "+spec.Deprecated)
+		}
+		errs, warnings := validate(*spec, ing.Annotations[name], ing.Services)
+		for _, message := range errs {
+			report(SeverityError, message)
+		}
+		for _, message := range warnings {
+			report(SeverityWarning, message)
+		}
+	}
+	return findings
+}
+
+// unknown reports annotations of the ingress controller missing from the catalog and annotations whose
+// prefix looks like a typo of the ingress controller prefix
+func unknown(name string) (string, bool) {
+	prefix := strings.TrimSuffix(Prefix, "/")
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) != 2 {
+		return "", false
+	}
+	if parts[0] != prefix {
+		if distance(parts[0], prefix) > 2 {
+			return "", false
+		}
+		return fmt.Sprintf("unknown annotation prefix %v, did you mean %v?", parts[0], Prefix+parts[1]), true
+	}
+	message := "unknown annotation, it is ignored by the ingress controller"
+	best, bestDistance := "", 4
+	for _, spec := range catalog {
+		if !strings.HasPrefix(spec.Name, Prefix) {
+			continue
+		}
+		if d := distance(name, spec.Name); d < bestDistance {
+			best, bestDistance = spec.Name, d
+		}
+	}
+	if best != "" {
+		message += ", did you mean " + best + "?"
+	}
+	return message, true
+}
+
+// validate checks a value against the type of the annotation, it returns errors and warnings
+func validate(spec Spec, value string, services []string) ([]string, []string) {
+	value = strings.TrimSpace(value)
+	switch spec.Type {
+	case TypeIP:
+		if net.ParseIP(value) == nil {
+			return []string{fmt.Sprintf("%q is not an IP address", value)}, nil
+		}
+	case TypePort:
+		if port, err := strconv.Atoi(value); err != nil || port < 1 || port > 65535 {
+			return []string{fmt.Sprintf("%q is not a port number", value)}, nil
+		}
+	case TypeEnum:
+		if !containsFold(spec.Values, value) {
+			return []string{fmt.Sprintf("%q is not one of %v", value, strings.Join(spec.Values, ", "))}, nil
+		}
+	case TypeString:
+		if value == "" {
+			return []string{"value is empty"}, nil
+		}
+	case TypeJSON:
+		return validateJSON(spec, value, services)
+	}
+	return nil, nil
+}
+
+// validateJSON checks a JSON value against the layout of the annotation
+func validateJSON(spec Spec, value string, services []string) ([]string, []string) {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		return []string{"malformed JSON: " + err.Error()}, nil
+	}
+	object, ok := parsed.(map[string]interface{})
+	if !ok {
+		return []string{"value must be a JSON object"}, nil
+	}
+	errs, warnings := make([]string, 0), make([]string, 0)
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch spec.Layout {
+		case LayoutEntity:
+			if !isScalar(object[key]) {
+				errs = append(errs, fmt.Sprintf("attribute %v must be a string, number or boolean", key))
+			}
+		case LayoutEntityMap:
+			if !util.ContainsString(spec.Values, key) {
+				errs = append(errs, fmt.Sprintf("unknown key %v, expected one of %v", key, strings.Join(spec.Values, ", ")))
+				continue
+			}
+			errs = append(errs, validateEntity(key, object[key])...)
+		case LayoutServiceEntity, LayoutServiceValue:
+			if len(services) > 0 && !util.ContainsString(services, key) {
+				warnings = append(warnings, fmt.Sprintf("service %v is not a backend of the ingress", key))
+			}
+			if spec.Layout == LayoutServiceEntity {
+				errs = append(errs, validateEntity("service "+key, object[key])...)
+				continue
+			}
+			serviceValue, ok := object[key].(string)
+			if !ok {
+				errs = append(errs, fmt.Sprintf("value of service %v must be a string", key))
+			} else if len(spec.Values) > 0 && !containsFold(spec.Values, serviceValue) {
+				errs = append(errs, fmt.Sprintf("value %q of service %v is not one of %v", serviceValue, key, strings.Join(spec.Values, ", ")))
+			}
+		}
+	}
+	return errs, warnings
+}
+
+// validateEntity checks that a value is an object of entity attributes
+func validateEntity(what string, value interface{}) []string {
+	entity, ok := value.(map[string]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("value of %v must be a JSON object of attributes", what)}
+	}
+	errs := make([]string, 0)
+	for attribute, attributeValue := range entity {
+		if !isScalar(attributeValue) {
+			errs = append(errs, fmt.Sprintf("attribute %v of %v must be a string, number or boolean", attribute, what))
+		}
+	}
+	sort.Strings(errs)
+	return errs
+}
+
+// isScalar reports whether a JSON value is a string, number or boolean
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, float64, bool:
+		return true
+	}
+	return false
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// distance is the Levenshtein distance between two strings, used to suggest the intended annotation
+func distance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// minInt returns the smaller of two integers
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
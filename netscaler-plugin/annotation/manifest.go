@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package annotation
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// ReadManifest returns the Ingress resources of a YAML or JSON manifest, including the items of Lists,
+// with the line of their annotations
+func ReadManifest(source string, content []byte) ([]Ingress, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	ingresses := make([]Ingress, 0)
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return ingresses, fmt.Errorf("unable to parse %v: %v", source, err)
+		}
+		if len(doc.Content) > 0 {
+			ingresses = append(ingresses, readObject(source, doc.Content[0])...)
+		}
+	}
+	return ingresses, nil
+}
+
+// readObject returns the Ingress of a document, or the Ingresses of a List
+func readObject(source string, node *yaml.Node) []Ingress {
+	switch scalar(child(node, "kind")) {
+	case "Ingress":
+		metadata := child(node, "metadata")
+		ing := Ingress{
+			Source:      source,
+			Namespace:   scalar(child(metadata, "namespace")),
+			Name:        scalar(child(metadata, "name")),
+			Annotations: make(map[string]string),
+			Lines:       make(map[string]int),
+			Services:    make([]string, 0),
+		}
+		if annotations := child(metadata, "annotations"); annotations != nil && annotations.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(annotations.Content); i += 2 {
+				key, value := annotations.Content[i], annotations.Content[i+1]
+				ing.Annotations[key.Value] = value.Value
+				ing.Lines[key.Value] = key.Line
+				if value.Kind != yaml.ScalarNode || value.Tag != "!!str" {
+					ing.Unquoted = append(ing.Unquoted, key.Value)
+				}
+			}
+		}
+		backendServices(child(node, "spec"), &ing.Services)
+		return []Ingress{ing}
+	case "List", "IngressList":
+		ingresses := make([]Ingress, 0)
+		if items := child(node, "items"); items != nil {
+			for _, item := range items.Content {
+				ingresses = append(ingresses, readObject(source, item)...)
+			}
+		}
+		return ingresses
+	}
+	return nil
+}
+
+// backendServices collects the service names of networking.k8s.io/v1 and v1beta1 backends
+func backendServices(node *yaml.Node, services *[]string) {
+	if node == nil {
+		return
+	}
+	if node.Kind == yaml.MappingNode {
+		name := scalar(child(child(node, "service"), "name"))
+		if name == "" {
+			name = scalar(child(node, "serviceName"))
+		}
+		if name != "" && !util.ContainsString(*services, name) {
+			*services = append(*services, name)
+		}
+	}
+	for _, content := range node.Content {
+		backendServices(content, services)
+	}
+}
+
+// child returns the value of a key of a mapping node, nil when it is missing
+func child(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalar returns the value of a scalar node, empty when it is missing
+func scalar(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bundle serves Kubernetes objects, NetScaler configuration and logs from a support bundle
+// collected with the support subcommand, so that the plugin can run without cluster access.
+package bundle
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+)
+
+// Path of the support bundle given with --from-bundle, empty when the plugin runs against a cluster
+var Path string
+
+// Enabled reports whether the plugin runs against a support bundle
+func Enabled() bool {
+	return Path != ""
+}
+
+// NewClientset returns a clientset serving the Kubernetes objects saved in the support bundle
+func NewClientset() (k8sclient.Interface, error) {
+	info, err := os.Stat(Path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("support bundle %v is not a directory", Path)
+	}
+	clientSet := fake.NewSimpleClientset()
+	decoder := scheme.Codecs.UniversalDeserializer()
+	err = filepath.Walk(filepath.Join(Path, constant.KubeInfoDir), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() || !(strings.HasPrefix(name, "get_") || name == constant.CicDeployFile) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		obj, _, err := decoder.Decode(content, nil, nil)
+		if err != nil {
+			// Custom resources and empty dumps are not served
+			return nil
+		}
+		objects := []runtime.Object{obj}
+		if list, ok := obj.(*apiv1.List); ok {
+			objects = objects[:0]
+			for _, item := range list.Items {
+				itemObj, _, err := decoder.Decode(item.Raw, nil, nil)
+				if err == nil {
+					objects = append(objects, itemObj)
+				}
+			}
+		}
+		for _, object := range objects {
+			err = clientSet.Tracker().Add(object)
+			if err != nil && !apierrors.IsAlreadyExists(err) {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read support bundle %v: %v", Path, err)
+	}
+	return clientSet, nil
+}
+
+// ReadNetScalerFile returns a NetScaler output such as running configuration or status saved in the bundle
+func ReadNetScalerFile(name string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(Path, constant.BundleNSDir, name))
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("support bundle %v has no NetScaler %v, it was collected with an older plugin", Path, name)
+	}
+	return string(content), err
+}
+
+// OpenLogs opens the logs of a container of the controller in namespace saved in the bundle. Bundles collected with
+// older plugins only hold the ingress controller logs, which are opened when the bundle has no container logs.
+func OpenLogs(namespace string, container string, previous bool) (io.ReadCloser, error) {
+	dir := filepath.Join(Path, constant.KubeInfoDir, namespace, constant.CicLogsDir)
+	if HasContainerLogs(namespace) {
+		name := container + constant.LogsFileSuffix
+		if previous {
+			name = container + constant.PreviousLogsSuffix
+		}
+		logs, err := os.Open(filepath.Join(dir, constant.ContainerLogsDir, name))
+		if os.IsNotExist(err) {
+			return nil, missingLogsError(fmt.Sprintf("support bundle %v has no %v logs of container %v", Path, previousOrCurrent(previous), container))
+		}
+		return logs, err
+	}
+	name := constant.CicLogsFile
+	if previous {
+		name = constant.CicLogsRestart
+	}
+	return os.Open(filepath.Join(dir, name))
+}
+
+// HasContainerLogs tells whether the bundle holds the logs of every container of the controller in namespace
+func HasContainerLogs(namespace string) bool {
+	info, err := os.Stat(filepath.Join(Path, constant.KubeInfoDir, namespace, constant.CicLogsDir, constant.ContainerLogsDir))
+	return err == nil && info.IsDir()
+}
+
+// missingLogsError is returned for logs which are not saved in the bundle, it matches fs.ErrNotExist with errors.Is
+type missingLogsError string
+
+func (err missingLogsError) Error() string {
+	return string(err)
+}
+
+func (err missingLogsError) Is(target error) bool {
+	return target == fs.ErrNotExist
+}
+
+func previousOrCurrent(previous bool) string {
+	if previous {
+		return constant.PreviousLogs
+	}
+	return constant.CurrentLogs
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgparmor "github.com/ProtonMail/go-crypto/openpgp/armor"
+	"golang.org/x/term"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// Headers identifying age and OpenPGP keys and encrypted files
+const (
+	ageHeader      = "age-encryption.org/v1"
+	ageArmorHeader = "-----BEGIN AGE ENCRYPTED FILE-----"
+	pgpArmorPrefix = "-----BEGIN PGP"
+)
+
+// Recipients are the public keys a support bundle is encrypted to
+type Recipients struct {
+	// Extension of the encrypted archive, .age or .gpg
+	Extension string
+	age       []age.Recipient
+	pgp       openpgp.EntityList
+}
+
+// ReadRecipients reads age recipients, one per line, or an armored or binary OpenPGP public key ring
+func ReadRecipients(file string) (Recipients, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return Recipients{}, err
+	}
+	if bytes.Contains(content, []byte(pgpArmorPrefix)) {
+		keyRing, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(content))
+		if err != nil {
+			return Recipients{}, fmt.Errorf("unable to read the OpenPGP public key %v: %v", file, err)
+		}
+		return Recipients{Extension: ".gpg", pgp: keyRing}, nil
+	}
+	if recipients, err := age.ParseRecipients(bytes.NewReader(content)); err == nil {
+		return Recipients{Extension: ".age", age: recipients}, nil
+	}
+	keyRing, err := openpgp.ReadKeyRing(bytes.NewReader(content))
+	if err != nil {
+		return Recipients{}, fmt.Errorf("%v is neither an age recipient nor an OpenPGP public key", file)
+	}
+	return Recipients{Extension: ".gpg", pgp: keyRing}, nil
+}
+
+// encrypt returns a writer encrypting to the recipients, closing it completes the encrypted stream
+func (recipients Recipients) encrypt(w io.Writer) (io.WriteCloser, error) {
+	if len(recipients.age) > 0 {
+		return age.Encrypt(w, recipients.age...)
+	}
+	return openpgp.Encrypt(w, recipients.pgp, nil, nil, nil)
+}
+
+// Encrypt archives the support bundle directory as an encrypted tar.gz next to it and removes the directory,
+// so that no unencrypted copy is left. It returns the path of the encrypted archive.
+func Encrypt(dir string, recipients Recipients) (string, error) {
+	dir = filepath.Clean(dir)
+	archive := dir + ".tar.gz" + recipients.Extension
+	file, err := os.OpenFile(archive, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", err
+	}
+	err = writeEncrypted(file, dir, recipients)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(archive)
+		return "", err
+	}
+	return archive, os.RemoveAll(dir)
+}
+
+// writeEncrypted writes the encrypted tar.gz of dir, whose entries are relative to the parent of dir
+func writeEncrypted(w io.Writer, dir string, recipients Recipients) error {
+	encrypted, err := recipients.encrypt(w)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(encrypted)
+	tw := tar.NewWriter(gz)
+	parent := filepath.Dir(dir)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(parent, path)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err = tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	return encrypted.Close()
+}
+
+// Decrypt decrypts a support bundle archive with an age identity file or an OpenPGP private key and extracts
+// it in dir. The passphrase of an OpenPGP private key is read from NS_BUNDLE_PASSPHRASE or prompted for.
+func Decrypt(archive string, identityFile string, dir string) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	identity, err := os.ReadFile(identityFile)
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(file)
+	header, _ := reader.Peek(len(ageArmorHeader))
+	var plain io.Reader
+	switch {
+	case bytes.HasPrefix(header, []byte(ageHeader)) || bytes.HasPrefix(header, []byte(ageArmorHeader)):
+		identities, err := age.ParseIdentities(bytes.NewReader(identity))
+		if err != nil {
+			return fmt.Errorf("unable to read the age identity %v: %v", identityFile, err)
+		}
+		var src io.Reader = reader
+		if bytes.HasPrefix(header, []byte(ageArmorHeader)) {
+			src = armor.NewReader(reader)
+		}
+		plain, err = age.Decrypt(src, identities...)
+		if err != nil {
+			return err
+		}
+	default:
+		plain, err = decryptPGP(reader, header, identity, identityFile)
+		if err != nil {
+			return err
+		}
+	}
+	gz, err := gzip.NewReader(plain)
+	if err != nil {
+		return fmt.Errorf("%v is not an encrypted support bundle: %v", archive, err)
+	}
+	return extract(tar.NewReader(gz), dir)
+}
+
+// decryptPGP decrypts an armored or binary OpenPGP message with a private key ring
+func decryptPGP(reader io.Reader, header []byte, identity []byte, identityFile string) (io.Reader, error) {
+	var keyRing openpgp.EntityList
+	var err error
+	if bytes.Contains(identity, []byte(pgpArmorPrefix)) {
+		keyRing, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(identity))
+	} else {
+		keyRing, err = openpgp.ReadKeyRing(bytes.NewReader(identity))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the OpenPGP private key %v: %v", identityFile, err)
+	}
+	if bytes.HasPrefix(header, []byte(pgpArmorPrefix)) {
+		block, err := pgparmor.Decode(reader)
+		if err != nil {
+			return nil, err
+		}
+		reader = block.Body
+	}
+	prompted := false
+	prompt := func(keys []openpgp.Key, symmetric bool) ([]byte, error) {
+		if prompted || symmetric {
+			return nil, errors.New("unable to decrypt the OpenPGP private key, check the passphrase")
+		}
+		prompted = true
+		passphrase, err := readPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if key.PrivateKey != nil && key.PrivateKey.Encrypted {
+				key.PrivateKey.Decrypt(passphrase)
+			}
+		}
+		return nil, nil
+	}
+	message, err := openpgp.ReadMessage(reader, keyRing, prompt, nil)
+	if err != nil {
+		return nil, err
+	}
+	return message.UnverifiedBody, nil
+}
+
+// readPassphrase reads the passphrase of a private key from the environment or from the terminal
+func readPassphrase() ([]byte, error) {
+	if passphrase, ok := os.LookupEnv(constant.PassphraseEnv); ok {
+		return []byte(passphrase), nil
+	}
+	if !util.IsInteractive() {
+		return nil, fmt.Errorf("the OpenPGP private key is protected by a passphrase, set it in %v", constant.PassphraseEnv)
+	}
+	fmt.Print("Passphrase of the OpenPGP private key: ")
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	return passphrase, err
+}
+
+// extract writes the directories and regular files of a tar archive in dir, refusing entries outside of it
+func extract(tr *tar.Reader, dir string) error {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(root, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, root+string(os.PathSeparator)) {
+			return fmt.Errorf("entry %v is outside of the extraction directory", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, 0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err = os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(file, tr)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ciclog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// recordRegex matches the first line of an ingress controller log record, for example
+// 2023-04-10 03:29:54,720  - INFO - [nitrointerface.py:configure_lb:2018] (MainThread) message
+var recordRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}:\d{2}(?:[,.]\d+)?)\s+-\s+([A-Z]+)\s+-\s+\[([^\]]*)\]\s+\(([^)]*)\)\s?(.*)$`)
+
+// levels orders the python logging levels used by the ingress controller
+var levels = map[string]int{
+	"DEBUG":    10,
+	"INFO":     20,
+	"WARNING":  30,
+	"WARN":     30,
+	"ERROR":    40,
+	"CRITICAL": 50,
+}
+
+// Record is a single ingress controller log record, continuation lines such as tracebacks included
+type Record struct {
+	Time    string
+	Level   string
+	Source  string
+	Thread  string
+	Message string
+	Lines   []string
+}
+
+// Text returns the record as it was logged
+func (rec Record) Text() string {
+	return strings.Join(rec.Lines, "\n")
+}
+
+// Parse reads log lines from r and calls fn for every record.
+// Lines which are not in the ingress controller format are returned as records without level.
+func Parse(r io.Reader, fn func(Record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	var current *Record
+	for scanner.Scan() {
+		line := scanner.Text()
+		match := recordRegex.FindStringSubmatch(line)
+		if match == nil && current != nil && current.Level != "" {
+			current.Lines = append(current.Lines, line)
+			current.Message += "\n" + line
+			continue
+		}
+		if current != nil {
+			if err := fn(*current); err != nil {
+				return err
+			}
+		}
+		if match == nil {
+			current = &Record{Message: line, Lines: []string{line}}
+		} else {
+			current = &Record{Time: match[1], Level: match[2], Source: match[3], Thread: match[4], Message: match[5], Lines: []string{line}}
+		}
+	}
+	if current != nil {
+		if err := fn(*current); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Filter selects log records by level and by the Kubernetes or NetScaler entities they mention
+type Filter struct {
+	Level     string
+	Ingress   string
+	Namespace string
+	Entity    string
+	level     int
+	words     []*regexp.Regexp
+}
+
+// NewFilter validates the level and compiles the filter
+func NewFilter(level string, ingress string, namespace string, entity string) (*Filter, error) {
+	filter := &Filter{Level: strings.ToUpper(level), Ingress: ingress, Namespace: namespace, Entity: entity}
+	if filter.Level != "" {
+		value, ok := levels[filter.Level]
+		if !ok {
+			return nil, fmt.Errorf("unsupported log level %v, supported levels are DEBUG, INFO, WARNING, ERROR and CRITICAL", level)
+		}
+		filter.level = value
+	}
+	for _, word := range []string{ingress, namespace} {
+		if word != "" {
+			filter.words = append(filter.words, regexp.MustCompile(`(^|[^\w.-])`+regexp.QuoteMeta(word)+`($|[^\w.-])`))
+		}
+	}
+	return filter, nil
+}
+
+// Match reports whether a record passes all filter criteria
+func (filter *Filter) Match(rec Record) bool {
+	if filter.level > 0 && levels[rec.Level] < filter.level {
+		return false
+	}
+	for _, word := range filter.words {
+		if !word.MatchString(rec.Message) {
+			return false
+		}
+	}
+	if filter.Entity != "" && !strings.Contains(rec.Message, filter.Entity) {
+		return false
+	}
+	return true
+}
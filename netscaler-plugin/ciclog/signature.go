@@ -0,0 +1,190 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ciclog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Signature describes a known failure which can be recognised in ingress controller logs
+type Signature struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Severity    string `json:"severity"`
+	Pattern     string `json:"pattern"`
+	Explanation string `json:"explanation"`
+	Fix         string `json:"fix"`
+	regex       *regexp.Regexp
+}
+
+// signatureFile is the layout of a user provided signature catalog in YAML or JSON
+type signatureFile struct {
+	Signatures []Signature `json:"signatures"`
+}
+
+// builtinSignatures is the catalog of failures support looks for first when a case is opened
+var builtinSignatures = []Signature{
+	// NITRO answers a failed login with errorcode 354 and the HTTP error of the NITRO client is 401 Client Error, the
+	// Kubernetes client reports a 401 of the API server as (401) Reason: Unauthorized, which is not a NITRO failure
+	{
+		ID:          "nitro-unauthorized",
+		Title:       "NetScaler rejected the NITRO credentials",
+		Severity:    "critical",
+		Pattern:     `(?i)(errorcode\W+354\b|Invalid username or password|401 Client Error)`,
+		Explanation: "The ingress controller could not log in to NetScaler, so no configuration is applied.",
+		Fix:         "Verify the username and password in the secret referenced by NS_USER and NS_PASSWORD and that the user has superuser or the required command policy on NetScaler.",
+	},
+	{
+		ID:          "nitro-unreachable",
+		Title:       "NetScaler is not reachable from the ingress controller",
+		Severity:    "critical",
+		Pattern:     `(?i)(Max retries exceeded|Failed to establish a new connection|Connection refused|Connection timed out|Read timed out)`,
+		Explanation: "NITRO requests to NetScaler fail at the network level.",
+		Fix:         "Check NS_IP and NS_PORT of the ingress controller, routing and network policies between the pod and the NetScaler management or SNIP address.",
+	},
+	{
+		ID:          "resource-exists",
+		Title:       "NetScaler entity already exists",
+		Severity:    "warning",
+		Pattern:     `(?i)(Resource already exists|errorcode\W+273\b)`,
+		Explanation: "An entity with the same name exists on NetScaler, usually left over by another ingress controller or a previous deployment using the same prefix.",
+		Fix:         "Use a unique NS_APPS_NAME_PREFIX per ingress controller, or remove the stale entities with the config-cleanup tool.",
+	},
+	// The NITRO error of an sslcertkey operation, or the NetScaler messages for an unusable certificate or key
+	{
+		ID:          "certificate-upload",
+		Title:       "Certificate upload or certkey creation failed",
+		Severity:    "critical",
+		Pattern:     `(?i)(sslcertkey\S*\s.*errorcode\W+[1-9]|(add|update|bind|link)\W+sslcertkey\b.*\bfail|Certificate and private key do not match|Invalid (certificate|private key)\b)`,
+		Explanation: "The TLS secret of an ingress could not be installed on NetScaler, so the SSL vserver has no certificate bound.",
+		Fix:         "Verify that the secret holds a valid PEM certificate and matching key, that the key is not encrypted, and that /nsconfig/ssl on NetScaler is not full.",
+	},
+	{
+		ID:          "apiserver-watch",
+		Title:       "Kubernetes API server watch or list failed",
+		Severity:    "critical",
+		Pattern:     `(?i)(forbidden: User "system:serviceaccount|(watch|list)\S*\s.*(failed|forbidden|error)|ApiException|Failed to (watch|list))`,
+		Explanation: "The ingress controller cannot watch Kubernetes resources and stops reconciling the affected kinds.",
+		Fix:         "Check the ClusterRole and ClusterRoleBinding of the ingress controller service account and the connectivity to the API server.",
+	},
+	{
+		ID:          "feature-not-licensed",
+		Title:       "NetScaler feature is not licensed or not enabled",
+		Severity:    "warning",
+		Pattern:     `(?i)(feature\S* (is )?not licensed|Feature\(s\) not enabled|errorcode\W+(1093|1097)\b)`,
+		Explanation: "NetScaler refused a configuration because the required feature is disabled or not part of the license.",
+		Fix:         "Enable the feature (for example LB, CS, SSL, REWRITE, RESPONDER) and verify the NetScaler license.",
+	},
+	{
+		ID:          "annotation-invalid",
+		Title:       "Invalid ingress annotation",
+		Severity:    "warning",
+		Pattern:     `(?i)(annotation\S*\s.*(invalid|error|not valid|failed to parse)|JSONDecodeError|Expecting (value|property name))`,
+		Explanation: "An ingress.citrix.com annotation could not be parsed and is ignored.",
+		Fix:         "Validate the annotation values, JSON annotations such as frontend-ip and secure-port must be valid JSON.",
+	},
+	// The NotFound message of the API server, such as services "frontend" not found
+	{
+		ID:          "backend-service-missing",
+		Title:       "Backend service or endpoints not found",
+		Severity:    "warning",
+		Pattern:     `(?i)\b(services|endpoints|endpointslices(\.discovery\.k8s\.io)?) \\?"[a-z0-9.-]+\\?" not found`,
+		Explanation: "An ingress references a service without endpoints, so the servicegroup has no members.",
+		Fix:         "Verify the service name and port in the ingress and that the service selector matches running pods.",
+	},
+}
+
+// Match is a signature found in a log source
+type Match struct {
+	Signature Signature `json:"signature"`
+	Source    string    `json:"source"`
+	Count     int       `json:"count"`
+	First     string    `json:"first"`
+	Last      string    `json:"last"`
+	Sample    string    `json:"sample"`
+}
+
+// Signatures returns the built-in catalog extended with the signatures of the given files.
+// A signature of a file replaces the built-in signature with the same ID.
+func Signatures(files ...string) ([]Signature, error) {
+	catalog := append([]Signature{}, builtinSignatures...)
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var userFile signatureFile
+		if err = yaml.Unmarshal(content, &userFile); err != nil {
+			return nil, fmt.Errorf("unable to parse signature file %v: %v", file, err)
+		}
+		for _, sig := range userFile.Signatures {
+			if sig.ID == "" || sig.Pattern == "" {
+				return nil, fmt.Errorf("signature file %v: every signature needs an id and a pattern", file)
+			}
+			replaced := false
+			for i := range catalog {
+				if catalog[i].ID == sig.ID {
+					catalog[i] = sig
+					replaced = true
+				}
+			}
+			if !replaced {
+				catalog = append(catalog, sig)
+			}
+		}
+	}
+	for i := range catalog {
+		regex, err := regexp.Compile(catalog[i].Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for signature %v: %v", catalog[i].ID, err)
+		}
+		catalog[i].regex = regex
+	}
+	return catalog, nil
+}
+
+// Analyze runs the signatures over the log records of r and returns the matches in catalog order
+func Analyze(r io.Reader, source string, signatures []Signature) ([]Match, error) {
+	found := make(map[string]*Match)
+	err := Parse(r, func(rec Record) error {
+		for _, sig := range signatures {
+			if sig.regex == nil || !sig.regex.MatchString(rec.Message) {
+				continue
+			}
+			match, ok := found[sig.ID]
+			if !ok {
+				match = &Match{Signature: sig, Source: source, First: rec.Time, Sample: rec.Lines[0]}
+				found[sig.ID] = match
+			}
+			match.Count++
+			match.Last = rec.Time
+		}
+		return nil
+	})
+	matches := make([]Match, 0, len(found))
+	for _, sig := range signatures {
+		if match, ok := found[sig.ID]; ok {
+			matches = append(matches, *match)
+		}
+	}
+	return matches, err
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cluster runs a subcommand concurrently against several kubeconfig contexts and groups the output
+// by context, so that the controllers of many clusters can be inspected in one run.
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/bundle"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nitro"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// errReported is returned by Run when contexts failed and their errors are already part of the JSON document
+var errReported = errors.New("contexts failed")
+
+// Flags holds the --contexts and --all-contexts arguments of a subcommand
+type Flags struct {
+	contexts *string
+	all      *bool
+}
+
+// Func runs a subcommand against the cluster of flags and writes its output to out. context is empty when
+// the subcommand runs against the current context only.
+type Func func(out io.Writer, flags *genericclioptions.ConfigFlags, context string) error
+
+// Result is the outcome of a subcommand in one context, used for the JSON output
+type Result struct {
+	Context string      `json:"context"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// AddFlags adds --contexts and --all-contexts to a subcommand
+func AddFlags(cmd *cobra.Command) *Flags {
+	return &Flags{
+		contexts: util.AddFlagStringP(cmd, []byte(constant.ContextsFlag)),
+		all:      util.AddFlagBoolP(cmd, []byte(constant.AllContextsFlag)),
+	}
+}
+
+// Enabled reports whether the subcommand runs against several contexts
+func (clusterFlags *Flags) Enabled() bool {
+	return *clusterFlags.all || strings.TrimSpace(*clusterFlags.contexts) != ""
+}
+
+// Run runs fn against the current context, or concurrently against each context given with --contexts or
+// --all-contexts. The output of each context is printed as a group in the order of the contexts, or as a JSON
+// array when jsonOutput is set. A context which fails does not stop the others, an error summarizes the
+// failed contexts.
+func Run(flags *genericclioptions.ConfigFlags, clusterFlags *Flags, jsonOutput bool, fn Func) error {
+	if !clusterFlags.Enabled() {
+		return fn(os.Stdout, flags, "")
+	}
+	if flags.Context != nil && *flags.Context != "" {
+		return errors.New("--context cannot be used with --contexts or --all-contexts")
+	}
+	if bundle.Enabled() || nitro.URL != "" {
+		return errors.New("--contexts and --all-contexts cannot be used with --from-bundle or --nitro-url")
+	}
+	contexts, err := resolveContexts(flags, clusterFlags)
+	if err != nil {
+		return err
+	}
+	// Concurrent runs cannot share the terminal to ask which pod to use
+	util.DisablePrompts()
+	outputs := make([]bytes.Buffer, len(contexts))
+	errs := make([]error, len(contexts))
+	done := make([]chan struct{}, len(contexts))
+	for i := range contexts {
+		done[i] = make(chan struct{})
+		go func(i int) {
+			defer close(done[i])
+			errs[i] = fn(&outputs[i], contextFlags(flags, contexts[i]), contexts[i])
+		}(i)
+	}
+	failed := make([]string, 0)
+	results := make([]Result, 0, len(contexts))
+	for i, context := range contexts {
+		<-done[i]
+		if errs[i] != nil {
+			failed = append(failed, context)
+		}
+		if jsonOutput {
+			results = append(results, jsonResult(context, outputs[i].Bytes(), errs[i]))
+			continue
+		}
+		// Groups are printed as soon as they and the ones before them are complete
+		if i > 0 {
+			fmt.Println()
+		}
+		if errs[i] != nil {
+			fmt.Printf("==> Context: %v (failed)\n", context)
+		} else {
+			fmt.Printf("==> Context: %v\n", context)
+		}
+		if output := strings.TrimRight(outputs[i].String(), "\n"); output != "" {
+			fmt.Println(output)
+		}
+		if errs[i] != nil {
+			fmt.Println("error: " + errs[i].Error())
+		}
+	}
+	if jsonOutput {
+		op, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(op))
+		// The errors are part of the JSON document
+		if len(failed) > 0 {
+			return errReported
+		}
+		return nil
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%v of %v contexts failed: %v", len(failed), len(contexts), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// PrintError prints the error returned by Run, unless the errors of the contexts are part of the JSON document
+func PrintError(err error) {
+	if !errors.Is(err, errReported) {
+		util.PrintError(err)
+	}
+}
+
+// Exit prints the error returned by Run and exits with status 1 when the subcommand ran against several contexts
+// and at least one of them failed, so that scripts can detect the failure
+func Exit(clusterFlags *Flags, err error) {
+	PrintError(err)
+	if err != nil && clusterFlags.Enabled() {
+		os.Exit(1)
+	}
+}
+
+// resolveContexts returns the contexts given with --contexts, or all the contexts of the kubeconfig sorted by name
+func resolveContexts(flags *genericclioptions.ConfigFlags, clusterFlags *Flags) ([]string, error) {
+	contexts := make([]string, 0)
+	if *clusterFlags.all {
+		rawConfig, err := flags.ToRawKubeConfigLoader().RawConfig()
+		if err != nil {
+			return nil, err
+		}
+		for context := range rawConfig.Contexts {
+			contexts = append(contexts, context)
+		}
+		sort.Strings(contexts)
+	} else {
+		for _, context := range strings.Split(*clusterFlags.contexts, ",") {
+			context = strings.TrimSpace(context)
+			if context != "" && !util.ContainsString(contexts, context) {
+				contexts = append(contexts, context)
+			}
+		}
+	}
+	if len(contexts) == 0 {
+		return nil, errors.New("no kubeconfig context found")
+	}
+	return contexts, nil
+}
+
+// contextFlags returns a copy of the kubectl flags selecting context. The flags cannot be copied by value as
+// they cache the client configuration of their context.
+func contextFlags(flags *genericclioptions.ConfigFlags, context string) *genericclioptions.ConfigFlags {
+	copied := genericclioptions.NewConfigFlags(true)
+	copied.CacheDir = flags.CacheDir
+	copied.KubeConfig = flags.KubeConfig
+	copied.ClusterName = flags.ClusterName
+	copied.AuthInfoName = flags.AuthInfoName
+	copied.Context = &context
+	copied.Namespace = flags.Namespace
+	copied.APIServer = flags.APIServer
+	copied.TLSServerName = flags.TLSServerName
+	copied.Insecure = flags.Insecure
+	copied.CertFile = flags.CertFile
+	copied.KeyFile = flags.KeyFile
+	copied.CAFile = flags.CAFile
+	copied.BearerToken = flags.BearerToken
+	copied.Impersonate = flags.Impersonate
+	copied.ImpersonateUID = flags.ImpersonateUID
+	copied.ImpersonateGroup = flags.ImpersonateGroup
+	copied.Username = flags.Username
+	copied.Password = flags.Password
+	copied.Timeout = flags.Timeout
+	copied.DisableCompression = flags.DisableCompression
+	copied.WrapConfigFn = flags.WrapConfigFn
+	return copied
+}
+
+// jsonResult embeds the JSON output of a context, or keeps it as text when it is not a JSON document
+func jsonResult(context string, output []byte, err error) Result {
+	result := Result{Context: context}
+	if trimmed := bytes.TrimSpace(output); len(trimmed) > 0 {
+		if json.Valid(trimmed) {
+			result.Result = json.RawMessage(trimmed)
+		} else {
+			result.Result = string(trimmed)
+		}
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyze
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/ciclog"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/kubectl"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// AnalyzeCmdFlag struct for cobra command arguments for analyze sub command
+type AnalyzeCmdFlag struct {
+	podSelector *request.PodSelector
+	signatures  *string
+	output      *string
+}
+
+// initAnalyzeCmdFlag initializes struct AnalyzeCmdFlag based on json based constants
+func initAnalyzeCmdFlag(flag *AnalyzeCmdFlag, cmd *cobra.Command) {
+	flag.podSelector = request.AddPodSelectorFlags(cmd)
+	flag.signatures = util.AddFlagStringP(cmd, []byte(constant.SignaturesFlag))
+	flag.output = util.AddFlagStringP(cmd, []byte(constant.OutputFlag))
+}
+
+// CreateCommand creates the cobra commands for analyze subcommand
+func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
+	analyzeCmdFlag := AnalyzeCmdFlag{}
+	cmd := &cobra.Command{
+		Use:   "analyze",
+		Short: "Search Ingress Controller logs for known issues and suggest fixes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			util.PrintError(analyze(flags, analyzeCmdFlag))
+			return nil
+		},
+	}
+	initAnalyzeCmdFlag(&analyzeCmdFlag, cmd)
+	return cmd
+}
+
+// analyze receives user inputs and runs the signature catalog over current and previous cic container logs
+func analyze(flags *genericclioptions.ConfigFlags, analyzeCmdFlag AnalyzeCmdFlag) error {
+	/*********************************************************
+	 * kClient cannot be initialized in main to be in sync   *
+	 * with Cobra behaviour for defered flags init post RunE *
+	 *********************************************************/
+	kClient, err := request.NewK8sClient(flags)
+	if err != nil {
+		fmt.Println("unable to init K8s Client: " + err.Error())
+		os.Exit(1)
+
+	}
+	signatures, err := ciclog.Signatures(*analyzeCmdFlag.signatures)
+	if err != nil {
+		return err
+	}
+	pod, cicContainer, _, err := kClient.ChoosePod(flags, *analyzeCmdFlag.podSelector)
+	if err != nil {
+		return err
+	}
+	var logArgs []string
+	if cicContainer != "" {
+		logArgs = []string{"-c", cicContainer}
+	}
+	matches := make([]ciclog.Match, 0)
+	for _, source := range []string{constant.CurrentLogs, constant.PreviousLogs} {
+		args := logArgs
+		if source == constant.PreviousLogs {
+			args = append([]string{"-p"}, logArgs...)
+		}
+		err = kubectl.PodLogs(flags, &pod, args, func(op io.Reader) error {
+			found, err := ciclog.Analyze(op, source, signatures)
+			matches = append(matches, found...)
+			return err
+		})
+		// Previous logs exist only when the container restarted
+		if err != nil && !(source == constant.PreviousLogs && kubectl.NoPreviousLogs(err)) {
+			return err
+		}
+	}
+	return printMatches(pod.Namespace+"/"+pod.Name, matches, *analyzeCmdFlag.output)
+}
+
+// printMatches reports the matched signatures with their explanation and suggested fix
+func printMatches(podName string, matches []ciclog.Match, output string) error {
+	if strings.EqualFold(output, "json") {
+		op, err := json.MarshalIndent(matches, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(op))
+		return nil
+	}
+	if len(matches) == 0 {
+		fmt.Println("No known issues found in Ingress Controller logs of pod " + podName)
+		return nil
+	}
+	fmt.Printf("Found %v known issues in Ingress Controller logs of pod %v\n", len(matches), podName)
+	for _, match := range matches {
+		sig := match.Signature
+		fmt.Printf("\n[%v] %v (%v)\n", sig.Severity, sig.Title, sig.ID)
+		fmt.Printf("  Seen %v times in %v logs, first at %v, last at %v\n", match.Count, match.Source, match.First, match.Last)
+		fmt.Printf("  Sample:        %v\n", match.Sample)
+		fmt.Printf("  Explanation:   %v\n", sig.Explanation)
+		fmt.Printf("  Suggested fix: %v\n", sig.Fix)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	supportbundle "github.com/netscaler/modern-apps-toolkit/netscaler-plugin/bundle"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// DecryptCmdFlag struct for cobra command arguments for bundle decrypt sub command
+type DecryptCmdFlag struct {
+	identity *string
+	dir      *string
+}
+
+// initDecryptCmdFlag initializes struct DecryptCmdFlag based on json based constants
+func initDecryptCmdFlag(flag *DecryptCmdFlag, cmd *cobra.Command) {
+	flag.identity = util.AddFlagStringP(cmd, []byte(constant.IdentityFlag))
+	flag.dir = util.AddFlagStringP(cmd, []byte(constant.ExtractDirFlag))
+}
+
+// CreateCommand creates the cobra commands for bundle subcommand
+func CreateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Manage support bundles collected with the support subcommand",
+	}
+	cmd.AddCommand(createDecryptCommand())
+	return cmd
+}
+
+// createDecryptCommand creates the bundle decrypt subcommand
+func createDecryptCommand() *cobra.Command {
+	decryptCmdFlag := DecryptCmdFlag{}
+	cmd := &cobra.Command{
+		Use:   "decrypt ARCHIVE",
+		Short: "Decrypt a support bundle encrypted with support --encrypt-to and extract it for analysis with --from-bundle",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			util.PrintError(decrypt(args[0], decryptCmdFlag))
+			return nil
+		},
+	}
+	initDecryptCmdFlag(&decryptCmdFlag, cmd)
+	return cmd
+}
+
+// decrypt extracts an encrypted support bundle in the given directory
+func decrypt(archive string, decryptCmdFlag DecryptCmdFlag) error {
+	if *decryptCmdFlag.identity == "" {
+		return errors.New("--identity is required, give the age identity or the OpenPGP private key of the recipient")
+	}
+	dir := *decryptCmdFlag.dir
+	if len(dir) == 0 {
+		var err error
+		dir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+	if err := supportbundle.Decrypt(archive, *decryptCmdFlag.identity, dir); err != nil {
+		return err
+	}
+	fmt.Println("The support bundle is extracted in " + dir)
+	return nil
+}
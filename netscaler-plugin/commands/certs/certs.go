@@ -0,0 +1,297 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/bundle"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/kubectl"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nitro"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nsconf"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nsdata"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// Issues reported for a certificate
+const (
+	issueSecretMissing  = "SECRET MISSING"
+	issueInvalid        = "INVALID"
+	issueExpired        = "EXPIRED"
+	issueSerialMismatch = "SERIAL MISMATCH"
+	issueNoCertKey      = "NO CERTKEY"
+	issueExpiring       = "EXPIRING"
+)
+
+// CertsCmdFlag struct for cobra command arguments for certs sub command
+type CertsCmdFlag struct {
+	podSelector *request.PodSelector
+	appns       *string
+	expiryDays  *int
+	prefix      *string
+	output      *string
+}
+
+// initCertsCmdFlag initializes struct CertsCmdFlag based on json based constants
+func initCertsCmdFlag(flag *CertsCmdFlag, cmd *cobra.Command) {
+	flag.podSelector = request.AddPodSelectorFlags(cmd)
+	flag.appns = util.AddFlagStringP(cmd, []byte(constant.IngressNSFlag))
+	flag.expiryDays = util.AddFlagIntP(cmd, []byte(constant.ExpiryDaysFlag))
+	flag.prefix = util.AddFlagStringP(cmd, []byte(constant.PrefixFlag))
+	flag.output = util.AddFlagStringP(cmd, []byte(constant.OutputFlag))
+}
+
+// CreateCommand creates the cobra commands for certs subcommand
+func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
+	certsCmdFlag := CertsCmdFlag{}
+	cmd := &cobra.Command{
+		Use:   "certs",
+		Short: "List the TLS certificates of the ingresses with their NetScaler certificate keys, and report missing, mismatched and expiring certificates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			util.PrintError(certs(flags, certsCmdFlag))
+			return nil
+		},
+	}
+	initCertsCmdFlag(&certsCmdFlag, cmd)
+	return cmd
+}
+
+// Cert is the inventory entry of a TLS secret referenced by an ingress
+type Cert struct {
+	Namespace string    `json:"namespace"`
+	Ingress   string    `json:"ingress"`
+	Secret    string    `json:"secret"`
+	Subject   string    `json:"subject,omitempty"`
+	SANs      []string  `json:"sans,omitempty"`
+	NotAfter  time.Time `json:"notAfter,omitempty"`
+	Serial    string    `json:"serial,omitempty"`
+	CertKey   string    `json:"certkey,omitempty"`
+	VServers  []string  `json:"vservers,omitempty"`
+	Issues    []string  `json:"issues"`
+	// NetScalerChecked tells whether the serial number and expiry of the certificate key were compared, which
+	// is only possible with --nitro-url since the running configuration has no certificate validity
+	NetScalerChecked bool `json:"netscalerChecked"`
+}
+
+// certs receives user inputs, reads the TLS secrets of the ingresses and matches them with the NetScaler certificate keys
+func certs(flags *genericclioptions.ConfigFlags, certsCmdFlag CertsCmdFlag) error {
+	/*********************************************************
+	 * kClient cannot be initialized in main to be in sync   *
+	 * with Cobra behaviour for defered flags init post RunE *
+	 *********************************************************/
+	kClient, err := request.NewK8sClient(flags)
+	if err != nil {
+		fmt.Println("unable to init K8s Client: " + err.Error())
+		os.Exit(1)
+
+	}
+	if bundle.Enabled() {
+		return errors.New("support bundles do not contain secrets, certs cannot run with --from-bundle")
+	}
+	certKeys, bindings, prefix, err := netScalerCertKeys(kClient, flags, *certsCmdFlag.podSelector, *certsCmdFlag.prefix)
+	if err != nil {
+		return err
+	}
+	ingresses, err := kClient.GetIngressDefinitions(flags, *certsCmdFlag.appns)
+	if err != nil {
+		return err
+	}
+	inventory := make([]Cert, 0)
+	for _, ingress := range ingresses {
+		for _, tls := range ingress.Spec.TLS {
+			// An ingress without secret name uses the default certificate of the controller
+			if tls.SecretName == "" {
+				continue
+			}
+			cert := Cert{Namespace: ingress.Namespace, Ingress: ingress.Name, Secret: tls.SecretName, Issues: make([]string, 0), NetScalerChecked: nitro.URL != ""}
+			secret, err := kClient.GetSecret(flags, ingress.Namespace, tls.SecretName)
+			if err != nil {
+				return err
+			}
+			if secret == nil {
+				cert.Issues = append(cert.Issues, issueSecretMissing)
+			} else if err = readCertificate(&cert, secret); err != nil {
+				cert.Issues = append(cert.Issues, issueInvalid)
+			}
+			matchCertKey(&cert, certKeys, bindings, prefix, *certsCmdFlag.expiryDays)
+			inventory = append(inventory, cert)
+		}
+	}
+	if strings.EqualFold(*certsCmdFlag.output, "json") {
+		op, err := json.MarshalIndent(inventory, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(op))
+		return nil
+	}
+	if len(inventory) == 0 {
+		fmt.Println("No ingress with TLS secrets found")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tINGRESS\tSECRET\tSUBJECT\tSANS\tEXPIRES\tCERTKEY\tVSERVERS\tSTATUS")
+	for _, cert := range inventory {
+		expires, status := "--", "OK"
+		if !cert.NotAfter.IsZero() {
+			expires = cert.NotAfter.Format("2006-01-02")
+		}
+		if len(cert.Issues) > 0 {
+			status = strings.Join(cert.Issues, ",")
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", cert.Namespace, cert.Ingress, cert.Secret, orNone(cert.Subject),
+			orNone(strings.Join(cert.SANs, ",")), expires, orNone(cert.CertKey), orNone(strings.Join(cert.VServers, ",")), status)
+	}
+	w.Flush()
+	if nitro.URL == "" {
+		fmt.Println("\nThe serial numbers and expiry of the NetScaler certificate keys are not checked, the running configuration has no" +
+			" certificate validity. The status only covers the certificates of the secrets, use --nitro-url to compare them with NetScaler.")
+	}
+	return nil
+}
+
+// netScalerCertKeys returns the certificate keys and their vserver bindings, from the --nitro-url endpoint
+// with serial numbers and expiry, otherwise from the running configuration of the ingress controller. The prefix
+// of the certificate key names is the given one, otherwise the one of the ingress controller.
+func netScalerCertKeys(kClient request.K8sClient, flags *genericclioptions.ConfigFlags, selector request.PodSelector, prefix string) ([]nsconf.Object, []nsconf.Object, string, error) {
+	var pod apiv1.Pod
+	var cicContainer string
+	if nitro.URL == "" {
+		var err error
+		pod, cicContainer, _, err = kClient.ChoosePod(flags, selector)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		validVer, mismatch, err := kubectl.ValidVersion(flags, pod, cicContainer)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if !validVer {
+			return nil, nil, "", errors.New(strings.TrimSpace(mismatch))
+		}
+		if prefix == "" {
+			prefix = request.ControllerPrefix(pod)
+		}
+	}
+	if prefix == "" {
+		prefix = constant.DefaultPrefix
+	}
+	snapshot, err := nsdata.Load(flags, &pod, cicContainer, "sslcertkey", "sslvserver_sslcertkey_binding")
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return snapshot.Get("sslcertkey"), snapshot.Get("sslvserver_sslcertkey_binding"), prefix, nil
+}
+
+// readCertificate fills the subject, SANs, expiry and serial number of the first certificate of a TLS secret
+func readCertificate(cert *Cert, secret *apiv1.Secret) error {
+	block, _ := pem.Decode(secret.Data[apiv1.TLSCertKey])
+	if block == nil {
+		return fmt.Errorf("secret %v/%v has no PEM certificate", secret.Namespace, secret.Name)
+	}
+	x509Cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return err
+	}
+	cert.Subject = x509Cert.Subject.CommonName
+	if cert.Subject == "" {
+		cert.Subject = x509Cert.Subject.String()
+	}
+	cert.SANs = append(cert.SANs, x509Cert.DNSNames...)
+	for _, ip := range x509Cert.IPAddresses {
+		cert.SANs = append(cert.SANs, ip.String())
+	}
+	cert.NotAfter = x509Cert.NotAfter
+	cert.Serial = normalizeSerial(x509Cert.SerialNumber.Text(16))
+	return nil
+}
+
+// certKeyName returns the name of the certificate key the ingress controller creates for a TLS secret
+func certKeyName(prefix string, namespace string, secret string) string {
+	return prefix + "-" + secret + "_" + namespace + "_crt"
+}
+
+// matchCertKey finds the certificate key of a secret, by serial number when NetScaler reports it, otherwise by
+// the name the ingress controller gives it, and checks the expiry
+func matchCertKey(cert *Cert, certKeys []nsconf.Object, bindings []nsconf.Object, prefix string, expiryDays int) {
+	var matched nsconf.Object
+	name := certKeyName(prefix, cert.Namespace, cert.Secret)
+	for _, certKey := range certKeys {
+		serial := normalizeSerial(certKey.String("serial"))
+		if cert.Serial != "" && serial == cert.Serial {
+			matched = certKey
+			break
+		}
+		if matched == nil && certKey.String("certkey") == name {
+			matched = certKey
+		}
+	}
+	expired := !cert.NotAfter.IsZero() && !time.Now().Before(cert.NotAfter)
+	expiring := !cert.NotAfter.IsZero() && time.Until(cert.NotAfter) < time.Duration(expiryDays)*24*time.Hour
+	if matched == nil {
+		if !util.ContainsString(cert.Issues, issueSecretMissing) {
+			cert.Issues = append(cert.Issues, issueNoCertKey)
+		}
+	} else {
+		cert.CertKey = matched.String("certkey")
+		serial := normalizeSerial(matched.String("serial"))
+		if cert.Serial != "" && serial != "" && serial != cert.Serial {
+			cert.Issues = append(cert.Issues, issueSerialMismatch)
+		}
+		// NetScaler reports the expiry of the uploaded certificate, which may differ from the secret
+		if days, err := strconv.Atoi(matched.String("daystoexpiration")); err == nil {
+			expired = expired || days < 0
+			expiring = expiring || days < expiryDays
+		}
+		for _, binding := range bindings {
+			if binding.String("certkeyname") == cert.CertKey && !util.ContainsString(cert.VServers, binding.String("vservername")) {
+				cert.VServers = append(cert.VServers, binding.String("vservername"))
+			}
+		}
+	}
+	if expired {
+		cert.Issues = append(cert.Issues, issueExpired)
+	} else if expiring {
+		cert.Issues = append(cert.Issues, issueExpiring)
+	}
+}
+
+// normalizeSerial compares serial numbers written with or without colons and leading zeros
+func normalizeSerial(serial string) string {
+	return strings.TrimLeft(strings.ToUpper(strings.ReplaceAll(serial, ":", "")), "0")
+}
+
+// orNone prints -- for empty cells
+func orNone(value string) string {
+	if value == "" {
+		return "--"
+	}
+	return value
+}
@@ -21,24 +21,22 @@ import (
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/bundle"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/kubectl"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nitro"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
 )
 
 // ConfCmdFlag struct for cobra command arguments for conf sub command
 type ConfCmdFlag struct {
-	pod        *string
-	deployment *string
-	selector   *string
+	podSelector *request.PodSelector
 }
 
 // initConfCmdFlag initializes struct ConfCmdFlag based on json based constants
 func initConfCmdFlag(flag *ConfCmdFlag, cmd *cobra.Command) {
-	flag.pod = util.AddFlagStringP(cmd, []byte(constant.PodFlag))
-	flag.deployment = util.AddFlagStringP(cmd, []byte(constant.DeployFlag))
-	flag.selector = util.AddFlagStringP(cmd, []byte(constant.SelectorFlag))
+	flag.podSelector = request.AddPodSelectorFlags(cmd)
 }
 
 // CreateCommand creates the cobra commands for conf subcommand
@@ -58,6 +56,13 @@ func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
 
 // conf receives user inputs and filters kubernetes object and runs plugin file with conf sub in cic container
 func conf(flags *genericclioptions.ConfigFlags, confCmdFlag ConfCmdFlag) error {
+	if nitro.URL != "" {
+		op, err := kubectl.NetScalerConf(flags, nil, "")
+		if op != "" {
+			fmt.Print("\n" + op)
+		}
+		return err
+	}
 	/*********************************************************
 	 * kClient cannot be initialized in main to be in sync   *
 	 * with Cobra behaviour for defered flags init post RunE *
@@ -68,10 +73,17 @@ func conf(flags *genericclioptions.ConfigFlags, confCmdFlag ConfCmdFlag) error {
 		os.Exit(1)
 
 	}
-	pod, cicContainer, _, err := kClient.ChoosePod(flags, *confCmdFlag.pod, *confCmdFlag.deployment, *confCmdFlag.selector)
+	pod, cicContainer, _, err := kClient.ChoosePod(flags, *confCmdFlag.podSelector)
 	if err != nil {
 		return err
 	}
+	if bundle.Enabled() {
+		op, err := bundle.ReadNetScalerFile(constant.BundleConfFile)
+		if op != "" {
+			fmt.Print("\n" + op)
+		}
+		return err
+	}
 	validVer, mismatch, err := kubectl.ValidVersion(flags, pod, cicContainer)
 	util.CmdErrorHandling(err)
 	if !validVer {
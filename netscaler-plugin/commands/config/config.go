@@ -0,0 +1,214 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/settings"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// SetTargetCmdFlag struct for cobra command arguments for config set-target sub command
+type SetTargetCmdFlag struct {
+	selector     *string
+	deployment   *string
+	daemonSet    *string
+	statefulSet  *string
+	owner        *string
+	pod          *string
+	prefix       *string
+	container    *string
+	cpxContainer *string
+	output       *string
+}
+
+// initSetTargetCmdFlag initializes struct SetTargetCmdFlag based on json based constants
+func initSetTargetCmdFlag(flag *SetTargetCmdFlag, cmd *cobra.Command) {
+	flag.selector = util.AddFlagStringP(cmd, []byte(constant.SelectorFlag))
+	flag.deployment = util.AddFlagStringP(cmd, []byte(constant.DeployFlag))
+	flag.daemonSet = util.AddFlagStringP(cmd, []byte(constant.DaemonSetFlag))
+	flag.statefulSet = util.AddFlagStringP(cmd, []byte(constant.StatefulSetFlag))
+	flag.owner = util.AddFlagStringP(cmd, []byte(constant.OwnerFlag))
+	flag.pod = util.AddFlagStringP(cmd, []byte(constant.PodFlag))
+	flag.prefix = util.AddFlagStringP(cmd, []byte(constant.PrefixFlag))
+	flag.container = util.AddFlagStringP(cmd, []byte(constant.ContainerFlag))
+	flag.cpxContainer = util.AddFlagStringP(cmd, []byte(constant.CPXContainerFlag))
+	flag.output = util.AddFlagStringP(cmd, []byte(constant.OutputFlag))
+}
+
+// CreateCommand creates the cobra commands for config subcommand
+func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "View and manage the targets of the plugin configuration file (" + settings.Path() + ")",
+		// The configuration file is not applied to the commands managing it
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+	cmd.AddCommand(createViewCommand())
+	cmd.AddCommand(createSetTargetCommand(flags))
+	cmd.AddCommand(createUseTargetCommand())
+	return cmd
+}
+
+// createViewCommand creates the config view subcommand
+func createViewCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "view",
+		Short: "Display the plugin configuration file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			util.PrintError(view())
+			return nil
+		},
+	}
+}
+
+// createSetTargetCommand creates the config set-target subcommand
+func createSetTargetCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
+	setTargetCmdFlag := SetTargetCmdFlag{}
+	cmd := &cobra.Command{
+		Use:               "set-target NAME",
+		Short:             "Create or update a target with the given --context, --namespace, selector, prefix, containers and output, other fields are kept",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTargets,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			util.PrintError(setTarget(cmd, flags, args[0], setTargetCmdFlag))
+			return nil
+		},
+	}
+	initSetTargetCmdFlag(&setTargetCmdFlag, cmd)
+	return cmd
+}
+
+// createUseTargetCommand creates the config use-target subcommand
+func createUseTargetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:               "use-target NAME",
+		Short:             "Make a target the current target, used when --target is not given",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTargets,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			util.PrintError(useTarget(args[0]))
+			return nil
+		},
+	}
+}
+
+// completeTargets completes the name of a target of the configuration file
+func completeTargets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	config, err := settings.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := make([]string, 0, len(config.Targets))
+	for name := range config.Targets {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// view prints the plugin configuration file
+func view() error {
+	if _, err := os.Stat(settings.Path()); os.IsNotExist(err) {
+		fmt.Println("No plugin configuration file found at " + settings.Path())
+		return nil
+	}
+	config, err := settings.Load()
+	if err != nil {
+		return err
+	}
+	op, err := settings.Marshal(config)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(op))
+	return nil
+}
+
+// setTarget creates or updates a target with the flags given on the command line
+func setTarget(cmd *cobra.Command, flags *genericclioptions.ConfigFlags, name string, setTargetCmdFlag SetTargetCmdFlag) error {
+	config, err := settings.Load()
+	if err != nil {
+		return err
+	}
+	if config.Targets == nil {
+		config.Targets = make(map[string]settings.Target)
+	}
+	target := config.Targets[name]
+	if flags.Context != nil && *flags.Context != "" {
+		target.Context = *flags.Context
+	}
+	if flags.Namespace != nil && *flags.Namespace != "" {
+		target.Namespace = *flags.Namespace
+	}
+	set := func(flag string, field *string, value *string) {
+		if cmd.Flags().Changed(flag) {
+			*field = *value
+		}
+	}
+	set("label", &target.Selector, setTargetCmdFlag.selector)
+	set("deployment", &target.Deployment, setTargetCmdFlag.deployment)
+	set("daemonset", &target.DaemonSet, setTargetCmdFlag.daemonSet)
+	set("statefulset", &target.StatefulSet, setTargetCmdFlag.statefulSet)
+	set("owner", &target.Owner, setTargetCmdFlag.owner)
+	set("pod", &target.Pod, setTargetCmdFlag.pod)
+	set("prefix", &target.Prefix, setTargetCmdFlag.prefix)
+	set("container", &target.Container, setTargetCmdFlag.container)
+	set("cpx-container", &target.CPXContainer, setTargetCmdFlag.cpxContainer)
+	set("output", &target.Output, setTargetCmdFlag.output)
+	if target == (settings.Target{}) {
+		return errors.New("target " + name + " is empty, give at least one of --context, --namespace, --label, --deployment, --daemonset, --statefulset, --owner, --pod, --prefix, --container, --cpx-container and --output")
+	}
+	config.Targets[name] = target
+	if err = settings.Save(config); err != nil {
+		return err
+	}
+	fmt.Printf("Target %v set in %v\n", name, settings.Path())
+	return nil
+}
+
+// useTarget sets the current target of the configuration file
+func useTarget(name string) error {
+	config, err := settings.Load()
+	if err != nil {
+		return err
+	}
+	if _, ok := config.Targets[name]; !ok {
+		return fmt.Errorf("target %v not found in %v", name, settings.Path())
+	}
+	config.CurrentTarget = name
+	if err = settings.Save(config); err != nil {
+		return err
+	}
+	fmt.Printf("Switched to target %v\n", name)
+	return nil
+}
@@ -0,0 +1,427 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gslb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/kubectl"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nitro"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nsconf"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nsdata"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// GslbCmdFlag struct for cobra command arguments for gslb sub command
+type GslbCmdFlag struct {
+	podSelector *request.PodSelector
+	appns       *string
+	output      *string
+}
+
+// initGslbCmdFlag initializes struct GslbCmdFlag based on json based constants
+func initGslbCmdFlag(flag *GslbCmdFlag, cmd *cobra.Command) {
+	flag.podSelector = request.AddPodSelectorFlags(cmd)
+	flag.podSelector.Kind = constant.KindGSLB
+	flag.appns = util.AddFlagStringP(cmd, []byte(constant.IngressNSFlag))
+	flag.output = util.AddFlagStringP(cmd, []byte(constant.OutputFlag))
+}
+
+// CreateCommand creates the cobra commands for gslb subcommand
+func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
+	gslbCmdFlag := GslbCmdFlag{}
+	cmd := &cobra.Command{
+		Use:   "gslb",
+		Short: "Display GlobalTrafficPolicy and GlobalServiceEntry resources with their NetScaler GSLB virtual servers, services and sites",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			util.PrintError(gslb(flags, gslbCmdFlag))
+			return nil
+		},
+	}
+	initGslbCmdFlag(&gslbCmdFlag, cmd)
+	return cmd
+}
+
+// NetScaler GSLB resource types read by the subcommand
+var gslbResources = []string{"gslbvserver", "gslbservice", "gslbsite", "gslbvserver_gslbservice_binding", "gslbvserver_domain_binding"}
+
+// lbMethods maps the traffic policies of a GlobalTrafficPolicy to the load balancing method of the GSLB virtual server
+var lbMethods = map[string]string{
+	"ROUNDROBIN": "ROUNDROBIN",
+	"RTT":        "RTT",
+	"STATIC":     "STATICPROXIMITY",
+}
+
+// Target is a destination of a traffic policy and the NetScaler GSLB service serving it
+type Target struct {
+	Destination string   `json:"destination"`
+	Weight      string   `json:"weight,omitempty"`
+	Endpoint    string   `json:"endpoint"`
+	Service     string   `json:"gslbservice"`
+	Site        string   `json:"site"`
+	State       string   `json:"state"`
+	Issues      []string `json:"issues"`
+}
+
+// Policy is a host of a GlobalTrafficPolicy and the NetScaler GSLB virtual server of its domain
+type Policy struct {
+	Namespace     string   `json:"namespace"`
+	Name          string   `json:"name"`
+	Host          string   `json:"host"`
+	TrafficPolicy string   `json:"trafficPolicy"`
+	VServer       string   `json:"gslbvserver"`
+	LBMethod      string   `json:"lbMethod"`
+	State         string   `json:"state"`
+	Targets       []Target `json:"targets"`
+	Issues        []string `json:"issues"`
+}
+
+// Site is a GSLB site with the health of its GSLB services
+type Site struct {
+	Name     string `json:"name"`
+	IP       string `json:"ip"`
+	Type     string `json:"type"`
+	Services int    `json:"services"`
+	Up       int    `json:"up"`
+	Health   string `json:"health"`
+}
+
+// Report is the output of the gslb subcommand
+type Report struct {
+	Policies []Policy `json:"policies"`
+	Sites    []Site   `json:"sites"`
+	// Unmanaged lists the GSLB virtual servers of domains which are not in any GlobalTrafficPolicy
+	Unmanaged []string `json:"unmanagedVServers"`
+	Live      bool     `json:"live"`
+}
+
+// gslb receives user inputs, reads the GSLB custom resources and compares them with the NetScaler GSLB entities
+func gslb(flags *genericclioptions.ConfigFlags, gslbCmdFlag GslbCmdFlag) error {
+	/*********************************************************
+	 * kClient cannot be initialized in main to be in sync   *
+	 * with Cobra behaviour for defered flags init post RunE *
+	 *********************************************************/
+	kClient, err := request.NewK8sClient(flags)
+	if err != nil {
+		fmt.Println("unable to init K8s Client: " + err.Error())
+		os.Exit(1)
+
+	}
+	gtps, err := kClient.GetCustomResources(flags, constant.CitrixGroup, constant.GTPResource, *gslbCmdFlag.appns)
+	if err != nil {
+		return err
+	}
+	gses, err := kClient.GetCustomResources(flags, constant.CitrixGroup, constant.GSEResource, *gslbCmdFlag.appns)
+	if err != nil {
+		return err
+	}
+	var pod apiv1.Pod
+	var container string
+	if nitro.URL == "" {
+		pod, container, _, err = kClient.ChoosePod(flags, *gslbCmdFlag.podSelector)
+		if err != nil {
+			return err
+		}
+		validVer, mismatch, err := kubectl.ValidVersion(flags, pod, container)
+		if err != nil {
+			return err
+		}
+		if !validVer {
+			return errors.New(strings.TrimSpace(mismatch))
+		}
+	}
+	snapshot, err := nsdata.Load(flags, &pod, container, gslbResources...)
+	if err != nil {
+		return err
+	}
+	report := compare(gtps, gses, snapshot)
+	if strings.EqualFold(*gslbCmdFlag.output, "json") {
+		op, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(op))
+		return nil
+	}
+	printReport(report)
+	return nil
+}
+
+// compare matches the hosts and targets of the policies with the GSLB virtual servers and services of NetScaler
+func compare(gtps []unstructured.Unstructured, gses []unstructured.Unstructured, snapshot nsdata.Snapshot) Report {
+	report := Report{Policies: make([]Policy, 0), Sites: make([]Site, 0), Unmanaged: make([]string, 0), Live: snapshot.Live}
+	endpoints := make(map[string]string)
+	for _, gse := range gses {
+		endpoint, _, _ := unstructured.NestedString(gse.Object, "spec", "endpoint", "ipv4address")
+		if endpoint == "" {
+			endpoint, _, _ = unstructured.NestedString(gse.Object, "spec", "endpoint", "domainName")
+		}
+		endpoints[gse.GetName()] = endpoint
+	}
+	hosts := make(map[string]bool)
+	for _, gtp := range gtps {
+		specHosts, _, _ := unstructured.NestedSlice(gtp.Object, "spec", "hosts")
+		for _, item := range specHosts {
+			spec, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			policy := Policy{Namespace: gtp.GetNamespace(), Name: gtp.GetName(), Targets: make([]Target, 0), Issues: make([]string, 0)}
+			policy.Host, _, _ = unstructured.NestedString(spec, "host")
+			policy.TrafficPolicy, _, _ = unstructured.NestedString(spec, "policy", "trafficPolicy")
+			hosts[normalizeDomain(policy.Host)] = true
+			comparePolicy(&policy, spec, endpoints, snapshot)
+			report.Policies = append(report.Policies, policy)
+		}
+	}
+	for _, binding := range snapshot.Get("gslbvserver_domain_binding") {
+		if !hosts[normalizeDomain(binding.String("domainname"))] {
+			report.Unmanaged = append(report.Unmanaged, binding.String("name")+" ("+binding.String("domainname")+")")
+		}
+	}
+	for _, site := range snapshot.Get("gslbsite") {
+		report.Sites = append(report.Sites, siteHealth(site, snapshot))
+	}
+	return report
+}
+
+// comparePolicy finds the GSLB virtual server bound to the domain of the host and the GSLB service of each target
+func comparePolicy(policy *Policy, spec map[string]interface{}, endpoints map[string]string, snapshot nsdata.Snapshot) {
+	for _, binding := range snapshot.Get("gslbvserver_domain_binding") {
+		if normalizeDomain(binding.String("domainname")) == normalizeDomain(policy.Host) {
+			policy.VServer = binding.String("name")
+			break
+		}
+	}
+	bound := make(map[string]bool)
+	if policy.VServer == "" {
+		policy.State = nsdata.StateUnknown
+		policy.Issues = append(policy.Issues, "no gslbvserver for the domain")
+	} else {
+		policy.State = snapshot.State("gslbvserver", policy.VServer)
+		if vserver := snapshot.Find("gslbvserver", policy.VServer); vserver != nil {
+			policy.LBMethod = strings.ToUpper(vserver.String("lbmethod"))
+			if policy.LBMethod == "" {
+				policy.LBMethod = "LEASTCONNECTION"
+			}
+		}
+		if method, ok := lbMethods[strings.ToUpper(policy.TrafficPolicy)]; ok && policy.LBMethod != "" && method != policy.LBMethod {
+			policy.Issues = append(policy.Issues, fmt.Sprintf("lbmethod %v instead of %v", policy.LBMethod, method))
+		}
+		if isDown(policy.State) {
+			policy.Issues = append(policy.Issues, "gslbvserver "+policy.State)
+		}
+		for _, binding := range snapshot.Get("gslbvserver_gslbservice_binding") {
+			if binding.String("name") == policy.VServer {
+				bound[binding.String("servicename")] = true
+			}
+		}
+	}
+	targets, _, _ := unstructured.NestedSlice(spec, "policy", "targets")
+	for _, item := range targets {
+		targetSpec, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		target := Target{Issues: make([]string, 0)}
+		target.Destination, _, _ = unstructured.NestedString(targetSpec, "destination")
+		if weight, ok := targetSpec["weight"]; ok {
+			target.Weight = fmt.Sprint(weight)
+		}
+		// A target without destination cannot be matched with a GlobalServiceEntry or a GSLB service
+		if target.Destination == "" {
+			target.State = nsdata.StateUnknown
+			target.Issues = append(target.Issues, "destination missing")
+			policy.Targets = append(policy.Targets, target)
+			continue
+		}
+		endpoint, ok := endpoints[target.Destination]
+		if !ok {
+			target.Issues = append(target.Issues, "GlobalServiceEntry missing")
+		}
+		target.Endpoint = endpoint
+		service := findService(target.Destination, endpoint, bound, snapshot)
+		if service == nil {
+			target.State = nsdata.StateUnknown
+			target.Issues = append(target.Issues, "gslbservice missing")
+			policy.Targets = append(policy.Targets, target)
+			continue
+		}
+		target.Service = service.String("servicename")
+		target.Site = service.String("sitename")
+		target.State = snapshot.State("gslbservice", target.Service)
+		if policy.VServer != "" && !bound[target.Service] {
+			target.Issues = append(target.Issues, "gslbservice not bound to "+policy.VServer)
+		}
+		if ip := service.String("ip"); ip != "" && endpoint != "" && ip != endpoint {
+			target.Issues = append(target.Issues, fmt.Sprintf("gslbservice IP %v instead of %v", ip, endpoint))
+		}
+		if isDown(target.State) {
+			target.Issues = append(target.Issues, "gslbservice "+target.State)
+		}
+		policy.Targets = append(policy.Targets, target)
+	}
+}
+
+// findService returns the GSLB service named after the destination, preferring the services bound to the
+// GSLB virtual server, otherwise the bound service with the IP of the GlobalServiceEntry
+func findService(destination string, endpoint string, bound map[string]bool, snapshot nsdata.Snapshot) nsconf.Object {
+	var byName, byIP nsconf.Object
+	for _, service := range snapshot.Get("gslbservice") {
+		name := service.String("servicename")
+		if namedAfter(name, destination) && (byName == nil || bound[name]) {
+			byName = service
+		}
+		if endpoint != "" && service.String("ip") == endpoint && bound[name] {
+			byIP = service
+		}
+	}
+	if byName != nil {
+		return byName
+	}
+	return byIP
+}
+
+// siteHealth counts the GSLB services of a site which are up
+func siteHealth(obj nsconf.Object, snapshot nsdata.Snapshot) Site {
+	site := Site{Name: obj.String("sitename"), IP: obj.String("siteipaddress"), Type: obj.String("sitetype"), Health: nsdata.StateUnknown}
+	if site.Type == "" {
+		site.Type = "REMOTE"
+	}
+	for _, service := range snapshot.Get("gslbservice") {
+		if service.String("sitename") != site.Name {
+			continue
+		}
+		site.Services++
+		if strings.EqualFold(snapshot.State("gslbservice", service.String("servicename")), "UP") {
+			site.Up++
+		}
+	}
+	switch {
+	case !snapshot.Live || site.Services == 0:
+	case site.Up == site.Services:
+		site.Health = "UP"
+	case site.Up == 0:
+		site.Health = "DOWN"
+	default:
+		site.Health = "DEGRADED"
+	}
+	return site
+}
+
+// printReport prints the policies, their targets and the sites as tables
+func printReport(report Report) {
+	if len(report.Policies) == 0 {
+		fmt.Println("No GlobalTrafficPolicy found")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAMESPACE\tNAME\tHOST\tPOLICY\tGSLBVSERVER\tLBMETHOD\tSTATE\tISSUES")
+		for _, policy := range report.Policies {
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", policy.Namespace, policy.Name, policy.Host, policy.TrafficPolicy,
+				orNone(policy.VServer), orNone(policy.LBMethod), policy.State, orNone(strings.Join(policy.Issues, ", ")))
+		}
+		w.Flush()
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "HOST\tDESTINATION\tWEIGHT\tENDPOINT\tGSLBSERVICE\tSITE\tSTATE\tISSUES")
+		for _, policy := range report.Policies {
+			for _, target := range policy.Targets {
+				fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", policy.Host, orNone(target.Destination), orNone(target.Weight), orNone(target.Endpoint),
+					orNone(target.Service), orNone(target.Site), target.State, orNone(strings.Join(target.Issues, ", ")))
+			}
+		}
+		w.Flush()
+	}
+	if len(report.Sites) > 0 {
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SITE\tIP\tTYPE\tSERVICES\tUP\tHEALTH")
+		for _, site := range report.Sites {
+			up := "--"
+			if report.Live {
+				up = fmt.Sprint(site.Up)
+			}
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", site.Name, site.IP, site.Type, site.Services, up, site.Health)
+		}
+		w.Flush()
+	}
+	if len(report.Unmanaged) > 0 {
+		fmt.Println("\nGSLB virtual servers without GlobalTrafficPolicy: " + strings.Join(report.Unmanaged, ", "))
+	}
+	if !report.Live {
+		fmt.Println("\nStates are read from NetScaler only with --nitro-url, the running configuration has no runtime state")
+	}
+}
+
+// isDown reports whether a known state is not up
+func isDown(state string) bool {
+	return state != nsdata.StateUnknown && !strings.EqualFold(state, "UP")
+}
+
+// normalizeDomain compares domains case insensitively and without the trailing dot
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(domain), ".")
+}
+
+// namedAfter reports whether an entity name is the destination, or the destination with a prefix or suffix
+// separated by a delimiter such as app1.default.west.cluster2-gsvc. Entity names may replace the dots of
+// destinations, so the names are compared as their words of letters and digits.
+func namedAfter(name string, destination string) bool {
+	words, destWords := nameWords(name), nameWords(destination)
+	if len(destWords) == 0 || len(destWords) > len(words) {
+		return false
+	}
+	return equalWords(words[:len(destWords)], destWords) || equalWords(words[len(words)-len(destWords):], destWords)
+}
+
+// nameWords splits a name into its lowercase words of letters and digits
+func nameWords(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+}
+
+// equalWords compares two lists of words
+func equalWords(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// orNone prints -- for empty cells
+func orNone(value string) string {
+	if value == "" {
+		return "--"
+	}
+	return value
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/cluster"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/kubectl"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nsstatus"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// HealthCmdFlag struct for cobra command arguments for health sub command
+type HealthCmdFlag struct {
+	podSelector *request.PodSelector
+	ing         *string
+	prefix      *string
+	output      *string
+	minMembers  *int
+	maxRestarts *int
+	clusters    *cluster.Flags
+}
+
+// initHealthCmdFlag initializes struct HealthCmdFlag based on json based constants
+func initHealthCmdFlag(flag *HealthCmdFlag, cmd *cobra.Command) {
+	flag.podSelector = request.AddPodSelectorFlags(cmd)
+	flag.ing = util.AddFlagStringP(cmd, []byte(constant.IngressFlag))
+	flag.prefix = util.AddFlagStringP(cmd, []byte(constant.PrefixFlag))
+	flag.output = util.AddFlagStringP(cmd, []byte(constant.OutputFlag))
+	flag.minMembers = util.AddFlagIntP(cmd, []byte(constant.MinMembersFlag))
+	flag.maxRestarts = util.AddFlagIntP(cmd, []byte(constant.MaxRestartsFlag))
+	flag.clusters = cluster.AddFlags(cmd)
+}
+
+// CreateCommand creates the cobra commands for health subcommand
+func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
+	healthCmdFlag := HealthCmdFlag{}
+	cmd := &cobra.Command{
+		Use:   "health",
+		Short: "Check NetScaler entities, Kubernetes endpoints and the ingress controller pod, exit with a non-zero status when a threshold is violated",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var lock sync.Mutex
+			allHealthy := true
+			err := cluster.Run(flags, healthCmdFlag.clusters, strings.EqualFold(*healthCmdFlag.output, "json"),
+				func(out io.Writer, flags *genericclioptions.ConfigFlags, context string) error {
+					healthy, err := health(out, flags, healthCmdFlag)
+					lock.Lock()
+					defer lock.Unlock()
+					allHealthy = allHealthy && healthy && err == nil
+					return err
+				})
+			cluster.PrintError(err)
+			if err != nil || !allHealthy {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+	initHealthCmdFlag(&healthCmdFlag, cmd)
+	return cmd
+}
+
+// Check is the result of one health check
+type Check struct {
+	Check  string `json:"check"`
+	Object string `json:"object"`
+	Result string `json:"result"`
+	Detail string `json:"detail"`
+}
+
+// Report is the aggregated result of the health checks
+type Report struct {
+	Score  int     `json:"score"`
+	Status string  `json:"status"`
+	Checks []Check `json:"checks"`
+}
+
+// health runs the checks and prints the report, it returns false when a check failed
+func health(out io.Writer, flags *genericclioptions.ConfigFlags, healthCmdFlag HealthCmdFlag) (bool, error) {
+	/*********************************************************
+	 * kClient cannot be initialized in main to be in sync   *
+	 * with Cobra behaviour for defered flags init post RunE *
+	 *********************************************************/
+	kClient, err := request.NewK8sClient(flags)
+	if err != nil {
+		return false, errors.New("unable to init K8s Client: " + err.Error())
+	}
+	pod, cicContainer, _, err := kClient.ChoosePod(flags, *healthCmdFlag.podSelector)
+	if err != nil {
+		return false, err
+	}
+	validVer, mismatch, err := kubectl.ValidVersion(flags, pod, cicContainer)
+	if err != nil {
+		return false, err
+	}
+	if !validVer {
+		fmt.Fprint(out, mismatch)
+		return false, nil
+	}
+	checks := podChecks(pod, *healthCmdFlag.maxRestarts)
+	nsStatus, err := kubectl.NetScalerStatus(flags, &pod, cicContainer, *healthCmdFlag.prefix)
+	if err != nil {
+		return false, err
+	}
+	table := nsstatus.Parse(nsStatus)
+	if len(*healthCmdFlag.ing) > 0 {
+		table = table.FilterIngress(*healthCmdFlag.ing)
+	}
+	checks = append(checks, entityChecks(table, *healthCmdFlag.ing, *healthCmdFlag.minMembers)...)
+	endpoints, err := endpointChecks(kClient, flags, table.ServiceGroups())
+	if err != nil {
+		return false, err
+	}
+	checks = append(checks, endpoints...)
+	report := summarize(checks)
+	if strings.EqualFold(*healthCmdFlag.output, "json") {
+		op, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return false, err
+		}
+		fmt.Fprintln(out, string(op))
+	} else {
+		printReport(out, report)
+	}
+	return report.Status != "UNHEALTHY", nil
+}
+
+// podChecks checks the readiness and the restarts of the ingress controller pod
+func podChecks(pod apiv1.Pod, maxRestarts int) []Check {
+	object := pod.Namespace + "/" + pod.Name
+	ready, total, restarts := request.PodReadiness(pod)
+	readiness := Check{Check: "controller-ready", Object: object, Result: constant.HealthPass, Detail: fmt.Sprintf("%v/%v containers ready", ready, total)}
+	if total == 0 || ready < total {
+		readiness.Result = constant.HealthFail
+	}
+	restart := Check{Check: "controller-restarts", Object: object, Result: constant.HealthPass, Detail: fmt.Sprintf("%v restarts", restarts)}
+	if int(restarts) > maxRestarts {
+		restart.Result = constant.HealthFail
+		restart.Detail += fmt.Sprintf(", more than %v", maxRestarts)
+	}
+	return []Check{readiness, restart}
+}
+
+// entityChecks checks the state of the listeners, load balancers and service group members of the status table.
+// An ingress given with --ingress without NetScaler entities fails, it is misspelled or not configured on NetScaler.
+func entityChecks(table nsstatus.Table, ing string, minMembers int) []Check {
+	checks := make([]Check, 0)
+	if len(table.Rows) == 0 && ing != "" {
+		return append(checks, Check{Check: "netscaler-entities", Object: ing, Result: constant.HealthFail, Detail: "no NetScaler entities found for the ingress, check the ingress and prefix"})
+	}
+	if len(table.Rows) == 0 {
+		return append(checks, Check{Check: "netscaler-entities", Object: "--", Result: constant.HealthWarn, Detail: "no NetScaler entities found, check the ingress and prefix"})
+	}
+	for _, row := range table.Rows {
+		var name string
+		switch row.Get(nsstatus.ColResource) {
+		case nsstatus.ResourceListener:
+			name = "listener"
+		case nsstatus.ResourceLoadBalancer:
+			name = "lbvserver"
+		default:
+			continue
+		}
+		check := Check{Check: name, Object: row.Value(nsstatus.ColName), Result: constant.HealthPass, Detail: row.Get(nsstatus.ColStatus)}
+		if !row.Up() {
+			check.Result = constant.HealthFail
+		}
+		checks = append(checks, check)
+	}
+	for _, group := range table.ServiceGroups() {
+		up := 0
+		for _, member := range group.Members {
+			if member.Up() {
+				up++
+			}
+		}
+		check := Check{Check: "servicegroup-members", Object: group.Name, Result: constant.HealthPass, Detail: fmt.Sprintf("%v/%v members up", up, len(group.Members))}
+		switch {
+		case len(group.Members) == 0:
+			check.Result = constant.HealthFail
+			check.Detail = "no members"
+		case up*100 < minMembers*len(group.Members):
+			check.Result = constant.HealthFail
+			check.Detail += fmt.Sprintf(", less than %v%%", minMembers)
+		case up < len(group.Members):
+			check.Result = constant.HealthWarn
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// endpointChecks compares the service group members with the endpoints of the backend services of the ingresses
+func endpointChecks(kClient request.K8sClient, flags *genericclioptions.ConfigFlags, groups []*nsstatus.ServiceGroup) ([]Check, error) {
+	checks := make([]Check, 0)
+	ingresses := make(map[string][]networking.Ingress)
+	for _, group := range groups {
+		if group.Ingress == "" || group.Namespace == "" {
+			continue
+		}
+		if _, ok := ingresses[group.Namespace]; !ok {
+			list, err := kClient.GetIngressDefinitions(flags, group.Namespace)
+			if err != nil {
+				return checks, err
+			}
+			ingresses[group.Namespace] = list
+		}
+		for _, service := range request.BackendServices(ingresses[group.Namespace], group.Ingress, group.Port) {
+			count, err := kClient.GetNumEndpoints(flags, group.Namespace, service)
+			if err != nil {
+				return checks, err
+			}
+			addresses := 0
+			if count != nil {
+				addresses = *count
+			}
+			check := Check{Check: "endpoints", Object: group.Namespace + "/" + service, Result: constant.HealthPass, Detail: fmt.Sprintf("%v endpoints, %v members on NetScaler", addresses, len(group.Members))}
+			if addresses == 0 {
+				check.Result = constant.HealthFail
+			} else if addresses != len(group.Members) {
+				check.Result = constant.HealthWarn
+			}
+			checks = append(checks, check)
+		}
+	}
+	return checks, nil
+}
+
+// summarize computes the score, a warning counts as half a passed check
+func summarize(checks []Check) Report {
+	report := Report{Status: "HEALTHY", Checks: checks}
+	points := 0
+	for _, check := range checks {
+		switch check.Result {
+		case constant.HealthPass:
+			points += 2
+		case constant.HealthWarn:
+			points++
+			if report.Status == "HEALTHY" {
+				report.Status = "DEGRADED"
+			}
+		case constant.HealthFail:
+			report.Status = "UNHEALTHY"
+		}
+	}
+	if len(checks) > 0 {
+		report.Score = points * 50 / len(checks)
+	}
+	return report
+}
+
+// printReport prints the checks as a table followed by the score
+func printReport(out io.Writer, report Report) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tOBJECT\tRESULT\tDETAIL")
+	for _, check := range report.Checks {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", check.Check, check.Object, check.Result, check.Detail)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\nHealth score: %v%% (%v)\n", report.Score, report.Status)
+}
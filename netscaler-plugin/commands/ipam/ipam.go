@@ -0,0 +1,246 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/kubectl"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nitro"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nsdata"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// IpamCmdFlag struct for cobra command arguments for ipam sub command
+type IpamCmdFlag struct {
+	podSelector *request.PodSelector
+	appns       *string
+	output      *string
+}
+
+// initIpamCmdFlag initializes struct IpamCmdFlag based on json based constants
+func initIpamCmdFlag(flag *IpamCmdFlag, cmd *cobra.Command) {
+	flag.podSelector = request.AddPodSelectorFlags(cmd)
+	flag.appns = util.AddFlagStringP(cmd, []byte(constant.IngressNSFlag))
+	flag.output = util.AddFlagStringP(cmd, []byte(constant.OutputFlag))
+}
+
+// CreateCommand creates the cobra commands for ipam subcommand
+func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
+	ipamCmdFlag := IpamCmdFlag{}
+	cmd := &cobra.Command{
+		Use:   "ipam",
+		Short: "Display the VIPs allocated by the IPAM controller with their consumers and NetScaler virtual servers, and report duplicate and orphaned VIPs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			util.PrintError(ipam(flags, ipamCmdFlag))
+			return nil
+		},
+	}
+	initIpamCmdFlag(&ipamCmdFlag, cmd)
+	return cmd
+}
+
+// VIP is a vip custom resource with its consumer and the NetScaler virtual servers listening on its address
+type VIP struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Address   string   `json:"address"`
+	State     string   `json:"state"`
+	Consumer  string   `json:"consumer"`
+	LBStatus  []string `json:"loadBalancerStatus"`
+	VServers  []string `json:"vservers"`
+	Issues    []string `json:"issues"`
+}
+
+// ipam receives user inputs, reads the vip resources and checks their consumers and NetScaler virtual servers
+func ipam(flags *genericclioptions.ConfigFlags, ipamCmdFlag IpamCmdFlag) error {
+	/*********************************************************
+	 * kClient cannot be initialized in main to be in sync   *
+	 * with Cobra behaviour for defered flags init post RunE *
+	 *********************************************************/
+	kClient, err := request.NewK8sClient(flags)
+	if err != nil {
+		fmt.Println("unable to init K8s Client: " + err.Error())
+		os.Exit(1)
+
+	}
+	vips, err := kClient.GetCustomResources(flags, constant.CitrixGroup, constant.VIPResource, *ipamCmdFlag.appns)
+	if err != nil {
+		return err
+	}
+	services, err := kClient.GetNamespaceServices(flags, "")
+	if err != nil {
+		return err
+	}
+	ingresses, err := kClient.GetIngressDefinitions(flags, "")
+	if err != nil {
+		return err
+	}
+	var pod apiv1.Pod
+	var cicContainer string
+	if nitro.URL == "" {
+		pod, cicContainer, _, err = kClient.ChoosePod(flags, *ipamCmdFlag.podSelector)
+		if err != nil {
+			return err
+		}
+		validVer, mismatch, err := kubectl.ValidVersion(flags, pod, cicContainer)
+		if err != nil {
+			return err
+		}
+		if !validVer {
+			return errors.New(strings.TrimSpace(mismatch))
+		}
+	}
+	snapshot, err := nsdata.Load(flags, &pod, cicContainer, "lbvserver", "csvserver")
+	if err != nil {
+		return err
+	}
+	inventory := inspect(vips, services, ingresses, snapshot)
+	if strings.EqualFold(*ipamCmdFlag.output, "json") {
+		op, err := json.MarshalIndent(inventory, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(op))
+		return nil
+	}
+	if len(inventory) == 0 {
+		fmt.Println("No vip resource found")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tVIP\tSTATE\tCONSUMER\tLB STATUS\tVSERVERS\tISSUES")
+	for _, vip := range inventory {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", vip.Namespace, vip.Name, orNone(vip.Address), orNone(vip.State), orNone(vip.Consumer),
+			orNone(strings.Join(vip.LBStatus, ",")), orNone(strings.Join(vip.VServers, ",")), orNone(strings.Join(vip.Issues, ", ")))
+	}
+	w.Flush()
+	return nil
+}
+
+// inspect resolves the consumer of every vip and finds unassigned, duplicate and orphaned VIPs
+func inspect(vips []unstructured.Unstructured, services []apiv1.Service, ingresses []networking.Ingress, snapshot nsdata.Snapshot) []VIP {
+	inventory := make([]VIP, 0, len(vips))
+	owners := make(map[string][]string)
+	for _, obj := range vips {
+		vip := VIP{Namespace: obj.GetNamespace(), Name: obj.GetName(), LBStatus: make([]string, 0), VServers: make([]string, 0), Issues: make([]string, 0)}
+		vip.Address, _, _ = unstructured.NestedString(obj.Object, "spec", "ipaddress")
+		vip.State, _, _ = unstructured.NestedString(obj.Object, "status", "state")
+		if vip.State == "" {
+			vip.State, _, _ = unstructured.NestedString(obj.Object, "status", "status")
+		}
+		kind, _, _ := unstructured.NestedString(obj.Object, "spec", "kind")
+		if kind == "" {
+			kind = "LoadBalancer"
+		}
+		name, _, _ := unstructured.NestedString(obj.Object, "spec", "name")
+		namespace, _, _ := unstructured.NestedString(obj.Object, "spec", "namespace")
+		if namespace == "" {
+			namespace = obj.GetNamespace()
+		}
+		vip.Consumer = kind + " " + namespace + "/" + name
+		lbIngress, found := consumerStatus(kind, namespace, name, services, ingresses)
+		if !found {
+			vip.Issues = append(vip.Issues, "orphaned, consumer not found")
+		}
+		for _, lb := range lbIngress {
+			if lb.IP != "" {
+				vip.LBStatus = append(vip.LBStatus, lb.IP)
+			} else if lb.Hostname != "" {
+				vip.LBStatus = append(vip.LBStatus, lb.Hostname)
+			}
+		}
+		if vip.Address == "" {
+			vip.Issues = append(vip.Issues, "not allocated")
+			inventory = append(inventory, vip)
+			continue
+		}
+		owners[vip.Address] = append(owners[vip.Address], vip.Namespace+"/"+vip.Name)
+		if found && !util.ContainsString(vip.LBStatus, vip.Address) {
+			vip.Issues = append(vip.Issues, "address not in the load balancer status")
+		}
+		for _, resource := range []string{"csvserver", "lbvserver"} {
+			for _, vserver := range snapshot.Get(resource) {
+				if vserver.String("ipv46") == vip.Address {
+					vip.VServers = append(vip.VServers, vserver.String("name"))
+				}
+			}
+		}
+		if len(vip.VServers) == 0 {
+			vip.Issues = append(vip.Issues, "no NetScaler vserver")
+		}
+		inventory = append(inventory, vip)
+	}
+	for i, vip := range inventory {
+		if len(owners[vip.Address]) > 1 {
+			inventory[i].Issues = append(inventory[i].Issues, "duplicate of "+strings.Join(others(owners[vip.Address], vip.Namespace+"/"+vip.Name), ","))
+		}
+	}
+	return inventory
+}
+
+// consumerStatus returns the load balancer status of the Service or Ingress consuming a vip, and whether it exists
+func consumerStatus(kind string, namespace string, name string, services []apiv1.Service, ingresses []networking.Ingress) ([]apiv1.LoadBalancerIngress, bool) {
+	if strings.EqualFold(kind, "Ingress") {
+		for _, ingress := range ingresses {
+			if ingress.Namespace == namespace && ingress.Name == name {
+				lbIngress := make([]apiv1.LoadBalancerIngress, 0, len(ingress.Status.LoadBalancer.Ingress))
+				for _, lb := range ingress.Status.LoadBalancer.Ingress {
+					lbIngress = append(lbIngress, apiv1.LoadBalancerIngress{IP: lb.IP, Hostname: lb.Hostname})
+				}
+				return lbIngress, true
+			}
+		}
+		return nil, false
+	}
+	for _, svc := range services {
+		if svc.Namespace == namespace && svc.Name == name {
+			return svc.Status.LoadBalancer.Ingress, true
+		}
+	}
+	return nil, false
+}
+
+// others returns the owners of an address other than self
+func others(owners []string, self string) []string {
+	result := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		if owner != self {
+			result = append(result, owner)
+		}
+	}
+	return result
+}
+
+// orNone prints -- for empty cells
+func orNone(value string) string {
+	if value == "" {
+		return "--"
+	}
+	return value
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/annotation"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// LintCmdFlag struct for cobra command arguments for lint sub command
+type LintCmdFlag struct {
+	filename *string
+	appns    *string
+	output   *string
+}
+
+// initLintCmdFlag initializes struct LintCmdFlag based on json based constants
+func initLintCmdFlag(flag *LintCmdFlag, cmd *cobra.Command) {
+	flag.filename = util.AddFlagStringP(cmd, []byte(constant.FilenameFlag))
+	flag.appns = util.AddFlagStringP(cmd, []byte(constant.IngressNSFlag))
+	flag.output = util.AddFlagStringP(cmd, []byte(constant.OutputFlag))
+}
+
+// CreateCommand creates the cobra commands for lint subcommand
+func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
+	lintCmdFlag := LintCmdFlag{}
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check the ingress.citrix.com annotations of the Ingresses of the cluster or of manifest files, and report unknown, deprecated and malformed annotations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			util.PrintError(lint(flags, lintCmdFlag))
+			return nil
+		},
+	}
+	initLintCmdFlag(&lintCmdFlag, cmd)
+	return cmd
+}
+
+// lint receives user inputs, reads the Ingresses and reports the findings. The exit status is non-zero
+// when an annotation has an error so that manifests can be checked before they are applied.
+func lint(flags *genericclioptions.ConfigFlags, lintCmdFlag LintCmdFlag) error {
+	var ingresses []annotation.Ingress
+	var err error
+	if *lintCmdFlag.filename != "" {
+		ingresses, err = manifestIngresses(*lintCmdFlag.filename)
+	} else {
+		ingresses, err = clusterIngresses(flags, *lintCmdFlag.appns)
+	}
+	if err != nil {
+		return err
+	}
+	findings := make([]annotation.Finding, 0)
+	failed := false
+	for _, ing := range ingresses {
+		for _, finding := range annotation.Lint(ing) {
+			findings = append(findings, finding)
+			failed = failed || finding.Severity == annotation.SeverityError
+		}
+	}
+	if strings.EqualFold(*lintCmdFlag.output, "json") {
+		op, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(op))
+	} else if len(findings) == 0 {
+		fmt.Printf("No annotation issue found in %v ingresses\n", len(ingresses))
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SOURCE\tLINE\tNAMESPACE\tINGRESS\tANNOTATION\tSEVERITY\tMESSAGE")
+		for _, finding := range findings {
+			line := "--"
+			if finding.Line > 0 {
+				line = fmt.Sprint(finding.Line)
+			}
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", finding.Source, line, orNone(finding.Namespace), orNone(finding.Ingress),
+				finding.Annotation, finding.Severity, finding.Message)
+		}
+		w.Flush()
+	}
+	if failed {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// clusterIngresses reads the Ingresses of the cluster, all namespaces when namespace is empty
+func clusterIngresses(flags *genericclioptions.ConfigFlags, namespace string) ([]annotation.Ingress, error) {
+	/*********************************************************
+	 * kClient cannot be initialized in main to be in sync   *
+	 * with Cobra behaviour for defered flags init post RunE *
+	 *********************************************************/
+	kClient, err := request.NewK8sClient(flags)
+	if err != nil {
+		fmt.Println("unable to init K8s Client: " + err.Error())
+		os.Exit(1)
+
+	}
+	definitions, err := kClient.GetIngressDefinitions(flags, namespace)
+	if err != nil {
+		return nil, err
+	}
+	ingresses := make([]annotation.Ingress, 0, len(definitions))
+	for _, definition := range definitions {
+		ing := annotation.Ingress{
+			Source:      "cluster",
+			Namespace:   definition.Namespace,
+			Name:        definition.Name,
+			Annotations: definition.Annotations,
+			Services:    make([]string, 0),
+		}
+		for _, backend := range request.IngressBackends(definition) {
+			if !util.ContainsString(ing.Services, backend.Name) {
+				ing.Services = append(ing.Services, backend.Name)
+			}
+		}
+		ingresses = append(ingresses, ing)
+	}
+	return ingresses, nil
+}
+
+// manifestIngresses reads the Ingresses of comma separated files, directories or - for the standard input
+func manifestIngresses(filenames string) ([]annotation.Ingress, error) {
+	ingresses := make([]annotation.Ingress, 0)
+	for _, filename := range strings.Split(filenames, ",") {
+		filename = strings.TrimSpace(filename)
+		if filename == "" {
+			continue
+		}
+		if filename == "-" {
+			content, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return nil, err
+			}
+			read, err := annotation.ReadManifest("stdin", content)
+			if err != nil {
+				return nil, err
+			}
+			ingresses = append(ingresses, read...)
+			continue
+		}
+		err := filepath.Walk(filename, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			// Files of a directory are filtered by extension, files given explicitly are always read
+			ext := strings.ToLower(filepath.Ext(path))
+			if path != filename && ext != ".yaml" && ext != ".yml" && ext != ".json" {
+				return nil
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			read, err := annotation.ReadManifest(path, content)
+			if err != nil {
+				return err
+			}
+			ingresses = append(ingresses, read...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ingresses, nil
+}
+
+// orNone prints -- for empty cells
+func orNone(value string) string {
+	if value == "" {
+		return "--"
+	}
+	return value
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package list
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/cluster"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// listDocument is the JSON output of list
+type listDocument struct {
+	Controllers []request.Controller `json:"controllers"`
+	// UnclaimedIngresses is null when the ownership of the Ingresses is unknown
+	UnclaimedIngresses []request.IngressOwnership `json:"unclaimedIngresses"`
+}
+
+// ListCmdFlag struct for cobra command arguments for list sub command
+type ListCmdFlag struct {
+	allNamespaces *bool
+	output        *string
+	clusters      *cluster.Flags
+}
+
+// initListCmdFlag initializes struct ListCmdFlag based on json based constants
+func initListCmdFlag(flag *ListCmdFlag, cmd *cobra.Command) {
+	flag.allNamespaces = util.AddFlagBoolP(cmd, []byte(constant.AllNamespaceFlag))
+	flag.output = util.AddFlagStringP(cmd, []byte(constant.OutputFlag))
+	flag.clusters = cluster.AddFlags(cmd)
+}
+
+// CreateCommand creates the cobra commands for list subcommand
+func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
+	listCmdFlag := ListCmdFlag{}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List NetScaler Ingress, CPX, GSLB, IPAM and Gateway controllers deployed in the cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cluster.Exit(listCmdFlag.clusters, cluster.Run(flags, listCmdFlag.clusters, strings.EqualFold(*listCmdFlag.output, "json"),
+				func(out io.Writer, flags *genericclioptions.ConfigFlags, context string) error {
+					return list(out, flags, listCmdFlag)
+				}))
+			return nil
+		},
+	}
+	initListCmdFlag(&listCmdFlag, cmd)
+	return cmd
+}
+
+// list discovers NetScaler controller pods by image, env and labels and prints them
+func list(out io.Writer, flags *genericclioptions.ConfigFlags, listCmdFlag ListCmdFlag) error {
+	/*********************************************************
+	 * kClient cannot be initialized in main to be in sync   *
+	 * with Cobra behaviour for defered flags init post RunE *
+	 *********************************************************/
+	kClient, err := request.NewK8sClient(flags)
+	if err != nil {
+		return errors.New("unable to init K8s Client: " + err.Error())
+	}
+	controllers, err := kClient.DiscoverControllers(flags, *listCmdFlag.allNamespaces)
+	if err != nil {
+		return err
+	}
+	setLeaders(kClient, flags, controllers)
+	unclaimed, err := unclaimedIngresses(kClient, flags, controllers, *listCmdFlag.allNamespaces)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(*listCmdFlag.output, "json") {
+		op, err := json.MarshalIndent(listDocument{Controllers: controllers, UnclaimedIngresses: unclaimed}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(op))
+		return nil
+	}
+	if len(controllers) == 0 {
+		fmt.Fprintln(out, "No NetScaler controllers found")
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tTYPE\tMODE\tVERSION\tNETSCALER\tCLASSES\tLEADER\tHEALTH")
+	for _, ctrl := range controllers {
+		classes := "--"
+		if len(ctrl.Classes) > 0 {
+			classes = strings.Join(ctrl.Classes, ",")
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", ctrl.Namespace, ctrl.Name, ctrl.Kind, ctrl.Mode, ctrl.Version, ctrl.Target, classes, leaderState(ctrl), ctrl.Health)
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	return printUnclaimed(out, unclaimed, *listCmdFlag.allNamespaces)
+}
+
+// setLeaders fills the leader-election lock held by each controller or by another of its replicas. The locks of
+// a namespace which cannot be read, such as without permission on Leases, are skipped.
+func setLeaders(kClient request.K8sClient, flags *genericclioptions.ConfigFlags, controllers []request.Controller) {
+	pods := make([]apiv1.Pod, 0, len(controllers))
+	for _, ctrl := range controllers {
+		pods = append(pods, ctrl.Pod)
+	}
+	leaders := make(map[string][]request.Leader)
+	for i, ctrl := range controllers {
+		if _, ok := leaders[ctrl.Namespace]; !ok {
+			leaders[ctrl.Namespace], _ = kClient.GetLeaders(flags, ctrl.Namespace)
+		}
+		if leader, ok := request.ReplicaLeader(ctrl.Pod, pods, leaders[ctrl.Namespace]); ok {
+			controllers[i].Leader = &leader
+		}
+	}
+}
+
+// leaderState tells whether the controller is the leader, with the renew time and transitions of its lock, or a
+// standby of the leader
+func leaderState(ctrl request.Controller) string {
+	if ctrl.Leader == nil {
+		return "--"
+	}
+	if !ctrl.Leader.HeldBy(ctrl.Pod) {
+		return "standby of " + ctrl.Leader.Identity
+	}
+	return fmt.Sprintf("yes (%v, %v transitions)", ctrl.Leader.Renewed(), ctrl.Leader.Transitions)
+}
+
+// unclaimedIngresses returns the Ingresses of the namespace, or of all namespaces, which none of the discovered
+// ingress controllers claims. The ownership is unknown, and nil is returned, when the Ingresses or IngressClasses
+// cannot be read with the permissions of the user.
+func unclaimedIngresses(kClient request.K8sClient, flags *genericclioptions.ConfigFlags, controllers []request.Controller, allNamespaces bool) ([]request.IngressOwnership, error) {
+	namespace := ""
+	if !allNamespaces {
+		ns, err := util.GetNamespace(flags)
+		if err != nil {
+			return nil, err
+		}
+		namespace = ns
+	}
+	ownership, err := kClient.GetIngressOwnership(flags, namespace, controllers)
+	if apierrors.IsForbidden(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	unclaimed := make([]request.IngressOwnership, 0)
+	for _, owner := range ownership {
+		if owner.Unclaimed() {
+			unclaimed = append(unclaimed, owner)
+		}
+	}
+	return unclaimed, nil
+}
+
+// printUnclaimed lists the Ingresses which no NetScaler ingress controller claims. Without --all-namespaces, only
+// the controllers of the namespace are searched.
+func printUnclaimed(out io.Writer, unclaimed []request.IngressOwnership, allNamespaces bool) error {
+	if unclaimed == nil {
+		fmt.Fprintln(out, "\nThe Ingresses not claimed by any NetScaler ingress controller are unknown, the Ingresses or IngressClasses cannot be read")
+		return nil
+	}
+	if len(unclaimed) == 0 {
+		return nil
+	}
+	if allNamespaces {
+		fmt.Fprintln(out, "\nIngresses not claimed by any NetScaler ingress controller:")
+	} else {
+		fmt.Fprintln(out, "\nIngresses not claimed by any NetScaler ingress controller of the namespace:")
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tINGRESS\tCLASS")
+	for _, owner := range unclaimed {
+		class := owner.Class
+		if class == "" {
+			class = "--"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\n", owner.Namespace, owner.Name, class)
+	}
+	return w.Flush()
+}
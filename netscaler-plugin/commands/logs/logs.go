@@ -0,0 +1,210 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/bundle"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/ciclog"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/kubectl"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// LogsCmdFlag struct for cobra command arguments for logs sub command
+type LogsCmdFlag struct {
+	podSelector *request.PodSelector
+	containers  *string
+	since       *string
+	tail        *int
+	follow      *bool
+	previous    *bool
+	level       *string
+	ing         *string
+	appns       *string
+	entity      *string
+}
+
+// initLogsCmdFlag initializes struct LogsCmdFlag based on json based constants
+func initLogsCmdFlag(flag *LogsCmdFlag, cmd *cobra.Command) {
+	flag.podSelector = request.AddPodSelectorFlags(cmd)
+	flag.containers = util.AddFlagStringP(cmd, []byte(constant.ContainersFlag))
+	flag.since = util.AddFlagStringP(cmd, []byte(constant.SinceFlag))
+	flag.tail = util.AddFlagIntP(cmd, []byte(constant.TailFlag))
+	flag.follow = util.AddFlagBoolP(cmd, []byte(constant.FollowFlag))
+	flag.previous = util.AddFlagBoolP(cmd, []byte(constant.PreviousFlag))
+	flag.level = util.AddFlagStringP(cmd, []byte(constant.LevelFlag))
+	flag.ing = util.AddFlagStringP(cmd, []byte(constant.LogIngressFlag))
+	flag.appns = util.AddFlagStringP(cmd, []byte(constant.LogNSFlag))
+	flag.entity = util.AddFlagStringP(cmd, []byte(constant.EntityFlag))
+}
+
+// CreateCommand creates the cobra commands for logs subcommand
+func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
+	logsCmdFlag := LogsCmdFlag{}
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Display or stream Ingress Controller, CPX and exporter logs filtered by level, ingress, namespace or NetScaler entity",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			util.PrintError(logs(flags, logsCmdFlag))
+			return nil
+		},
+	}
+	initLogsCmdFlag(&logsCmdFlag, cmd)
+	return cmd
+}
+
+// logs receives user inputs, selects the controller pod and prints the filtered logs of the requested containers
+func logs(flags *genericclioptions.ConfigFlags, logsCmdFlag LogsCmdFlag) error {
+	/*********************************************************
+	 * kClient cannot be initialized in main to be in sync   *
+	 * with Cobra behaviour for defered flags init post RunE *
+	 *********************************************************/
+	kClient, err := request.NewK8sClient(flags)
+	if err != nil {
+		fmt.Println("unable to init K8s Client: " + err.Error())
+		os.Exit(1)
+
+	}
+	filter, err := ciclog.NewFilter(*logsCmdFlag.level, *logsCmdFlag.ing, *logsCmdFlag.appns, *logsCmdFlag.entity)
+	if err != nil {
+		return err
+	}
+	pod, cicContainer, cpxContainer, err := kClient.ChoosePod(flags, *logsCmdFlag.podSelector)
+	if err != nil {
+		return err
+	}
+	containers, err := resolveContainers(pod, cicContainer, cpxContainer, *logsCmdFlag.containers)
+	if err != nil {
+		return err
+	}
+	if bundle.Enabled() && len(*logsCmdFlag.since) > 0 {
+		return errors.New("--since cannot be used with --from-bundle, the saved logs are not relative to the current time, use --tail instead")
+	}
+	if bundle.Enabled() && !bundle.HasContainerLogs(pod.Namespace) {
+		cic, err := resolveContainers(pod, cicContainer, cpxContainer, constant.RoleCIC)
+		if err != nil || len(containers) != 1 || containers[0] != cic[0] {
+			return errors.New("support bundles collected with older plugins only contain Ingress Controller logs, use --containers=cic with --from-bundle")
+		}
+	}
+	logArgs := []string{"--tail=" + strconv.Itoa(*logsCmdFlag.tail)}
+	if len(*logsCmdFlag.since) > 0 {
+		logArgs = append(logArgs, "--since="+*logsCmdFlag.since)
+	}
+	if *logsCmdFlag.follow {
+		logArgs = append(logArgs, "-f")
+	}
+	if *logsCmdFlag.previous {
+		logArgs = append(logArgs, "-p")
+	}
+	out := &lockedWriter{writer: os.Stdout}
+	if !*logsCmdFlag.follow || len(containers) == 1 {
+		for _, container := range containers {
+			err = streamLogs(flags, pod, container, logArgs, filter, out, len(containers) > 1)
+			util.PrintError(err)
+		}
+		return nil
+	}
+	// Followed containers never finish, so they are streamed side by side
+	var wg sync.WaitGroup
+	for _, container := range containers {
+		wg.Add(1)
+		go func(container string) {
+			defer wg.Done()
+			util.PrintError(streamLogs(flags, pod, container, logArgs, filter, out, true))
+		}(container)
+	}
+	wg.Wait()
+	return nil
+}
+
+// streamLogs reads the logs of one container and writes the records matching the filter
+func streamLogs(flags *genericclioptions.ConfigFlags, pod apiv1.Pod, container string, logArgs []string, filter *ciclog.Filter, out io.Writer, prefixed bool) error {
+	args := append([]string{"-c", container}, logArgs...)
+	return kubectl.PodLogs(flags, &pod, args, func(op io.Reader) error {
+		return ciclog.Parse(op, func(rec ciclog.Record) error {
+			if !filter.Match(rec) {
+				return nil
+			}
+			text := rec.Text()
+			if prefixed {
+				text = "[" + container + "] " + strings.ReplaceAll(text, "\n", "\n["+container+"] ")
+			}
+			_, err := fmt.Fprintln(out, text)
+			return err
+		})
+	})
+}
+
+// resolveContainers maps the requested roles and names to the containers of the pod
+func resolveContainers(pod apiv1.Pod, cicContainer string, cpxContainer string, requested string) ([]string, error) {
+	containers := make([]string, 0)
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			containers = append(containers, name)
+		}
+	}
+	for _, item := range strings.Split(requested, ",") {
+		item = strings.TrimSpace(item)
+		found := false
+		for _, container := range pod.Spec.Containers {
+			image := strings.ToLower(container.Image)
+			switch {
+			case item == constant.RoleAll,
+				item == container.Name,
+				item == constant.RoleCPX && container.Name == cpxContainer,
+				item == constant.RoleCPX && cpxContainer == "" && strings.Contains(image, "cpx"),
+				item == constant.RoleExporter && strings.Contains(image, "exporter"),
+				item == constant.RoleCIC && container.Name == cicContainer,
+				item == constant.RoleCIC && cicContainer == "" && !strings.Contains(image, "cpx") && !strings.Contains(image, "exporter"):
+				add(container.Name)
+				found = true
+			}
+			if found && item == constant.RoleCIC {
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no %v container found in pod %v", item, pod.Name)
+		}
+	}
+	return containers, nil
+}
+
+// lockedWriter serializes writes of concurrently streamed containers
+type lockedWriter struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writer.Write(p)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// label is a name and value pair of a sample
+type label struct {
+	name  string
+	value string
+}
+
+// sample is one value of a gauge
+type sample struct {
+	labels []label
+	value  float64
+}
+
+// gauge is a metric family written in the Prometheus text exposition format
+type gauge struct {
+	name    string
+	help    string
+	samples []sample
+	seen    map[string]bool
+}
+
+// newGauge creates an empty gauge
+func newGauge(name string, help string) *gauge {
+	return &gauge{name: name, help: help, seen: make(map[string]bool)}
+}
+
+// set adds a sample, labels are given as name, value pairs and a repeated label set is ignored
+func (g *gauge) set(value float64, pairs ...string) {
+	labels := make([]label, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		labels = append(labels, label{name: pairs[i], value: pairs[i+1]})
+	}
+	key := formatLabels(labels)
+	if g.seen[key] {
+		return
+	}
+	g.seen[key] = true
+	g.samples = append(g.samples, sample{labels: labels, value: value})
+}
+
+// write prints the HELP and TYPE lines followed by the samples
+func (g *gauge) write(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v gauge\n", g.name, g.help, g.name)
+	if err != nil {
+		return err
+	}
+	for _, s := range g.samples {
+		_, err = fmt.Fprintf(w, "%v%v %v\n", g.name, formatLabels(s.labels), strconv.FormatFloat(s.value, 'g', -1, 64))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatLabels renders {name="value",...} with the escaping required by the exposition format
+func formatLabels(labels []label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	parts := make([]string, 0, len(labels))
+	for _, l := range labels {
+		parts = append(parts, l.name+`="`+escaper.Replace(l.value)+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/kubectl"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nsstatus"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// MetricsCmdFlag struct for cobra command arguments for serve-metrics sub command
+type MetricsCmdFlag struct {
+	podSelector *request.PodSelector
+	listen      *string
+	prefix      *string
+	interval    *string
+}
+
+// initMetricsCmdFlag initializes struct MetricsCmdFlag based on json based constants
+func initMetricsCmdFlag(flag *MetricsCmdFlag, cmd *cobra.Command) {
+	flag.podSelector = request.AddPodSelectorFlags(cmd)
+	flag.listen = util.AddFlagStringP(cmd, []byte(constant.MetricsAddrFlag))
+	flag.prefix = util.AddFlagStringP(cmd, []byte(constant.PrefixFlag))
+	flag.interval = util.AddFlagStringP(cmd, []byte(constant.IntervalFlag))
+}
+
+// CreateCommand creates the cobra commands for serve-metrics subcommand
+func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
+	metricsCmdFlag := MetricsCmdFlag{}
+	cmd := &cobra.Command{
+		Use:   "serve-metrics",
+		Short: "Poll the NetScaler entity status of the ingress controller and expose it as Prometheus metrics",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			util.PrintError(serveMetrics(flags, metricsCmdFlag))
+			return nil
+		},
+	}
+	initMetricsCmdFlag(&metricsCmdFlag, cmd)
+	return cmd
+}
+
+// serveMetrics selects the controller pod, polls its NetScaler entity status and serves the gauges on /metrics
+func serveMetrics(flags *genericclioptions.ConfigFlags, metricsCmdFlag MetricsCmdFlag) error {
+	/*********************************************************
+	 * kClient cannot be initialized in main to be in sync   *
+	 * with Cobra behaviour for defered flags init post RunE *
+	 *********************************************************/
+	kClient, err := request.NewK8sClient(flags)
+	if err != nil {
+		fmt.Println("unable to init K8s Client: " + err.Error())
+		os.Exit(1)
+
+	}
+	interval, err := time.ParseDuration(*metricsCmdFlag.interval)
+	if err != nil || interval <= 0 {
+		return fmt.Errorf("invalid interval %v, use a duration such as 30s or 1m", *metricsCmdFlag.interval)
+	}
+	pod, cicContainer, _, err := kClient.ChoosePod(flags, *metricsCmdFlag.podSelector)
+	if err != nil {
+		return err
+	}
+	validVer, mismatch, err := kubectl.ValidVersion(flags, pod, cicContainer)
+	if err != nil {
+		return err
+	}
+	if !validVer {
+		return errors.New(strings.TrimSpace(mismatch))
+	}
+	// The pod is chosen again without prompting when it is replaced while serving
+	util.DisablePrompts()
+	c := &collector{flags: flags, selector: *metricsCmdFlag.podSelector, pod: pod, cicContainer: cicContainer, prefix: *metricsCmdFlag.prefix}
+	c.poll()
+	go func() {
+		for range time.Tick(interval) {
+			c.poll()
+		}
+	}()
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", c)
+	fmt.Printf("Serving NetScaler entity metrics of pod %v/%v on %v/metrics every %v\n", pod.Namespace, pod.Name, *metricsCmdFlag.listen, interval)
+	return http.ListenAndServe(*metricsCmdFlag.listen, mux)
+}
+
+// collector keeps the gauges of the last poll
+type collector struct {
+	flags        *genericclioptions.ConfigFlags
+	selector     request.PodSelector
+	pod          apiv1.Pod
+	cicContainer string
+	prefix       string
+	mu           sync.Mutex
+	gauges       []*gauge
+	success      bool
+	lastPoll     time.Time
+}
+
+// poll refreshes the gauges, the entity gauges are dropped when the status cannot be read so that stale values are not exposed
+func (c *collector) poll() {
+	gauges, err := c.collect()
+	if err != nil {
+		fmt.Println("unable to poll NetScaler entity status: " + err.Error())
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gauges = gauges
+	c.success = err == nil
+	c.lastPoll = time.Now()
+}
+
+// ServeHTTP writes the gauges in the Prometheus text exposition format
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	success := newGauge("netscaler_status_poll_success", "Whether the last poll of the NetScaler entity status succeeded.")
+	success.set(boolValue(c.success))
+	lastPoll := newGauge("netscaler_status_last_poll_timestamp_seconds", "Time of the last poll of the NetScaler entity status.")
+	lastPoll.set(float64(c.lastPoll.Unix()))
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	for _, g := range append([]*gauge{success, lastPoll}, c.gauges...) {
+		if err := g.write(w); err != nil {
+			return
+		}
+	}
+}
+
+// collect reads the status table and computes the gauges
+func (c *collector) collect() ([]*gauge, error) {
+	out, err := kubectl.NetScalerStatus(c.flags, &c.pod, c.cicContainer, c.prefix)
+	if err != nil {
+		// The pod may have been replaced by a rollout, a restart, an eviction or a change of leader
+		if chooseErr := c.choosePod(); chooseErr != nil {
+			return nil, fmt.Errorf("%v, the controller pod cannot be chosen again: %v", err, chooseErr)
+		}
+		out, err = kubectl.NetScalerStatus(c.flags, &c.pod, c.cicContainer, c.prefix)
+		if err != nil {
+			return nil, err
+		}
+	}
+	table := nsstatus.Parse(out)
+	if table.Header == "" {
+		return nil, fmt.Errorf("unexpected status output: %v", strings.TrimSpace(out))
+	}
+	lbvserverUp := newGauge("netscaler_lbvserver_up", "Whether the NetScaler load balancing virtual server of an ingress is up.")
+	listenerUp := newGauge("netscaler_listener_up", "Whether the NetScaler content switching virtual server is up.")
+	memberUp := newGauge("netscaler_servicegroup_member_up", "Whether a member of the NetScaler service group of an ingress is up.")
+	for _, row := range table.Rows {
+		switch row.Get(nsstatus.ColResource) {
+		case nsstatus.ResourceLoadBalancer:
+			lbvserverUp.set(boolValue(row.Up()), "ingress", row.Value(nsstatus.ColIngress), "namespace", row.Value(nsstatus.ColNamespace), "name", row.Value(nsstatus.ColName))
+		case nsstatus.ResourceListener:
+			listenerUp.set(boolValue(row.Up()), "name", row.Value(nsstatus.ColName))
+		}
+	}
+	groups := table.ServiceGroups()
+	for _, group := range groups {
+		for _, member := range group.Members {
+			memberUp.set(boolValue(member.Up()), "ingress", group.Ingress, "namespace", group.Namespace, "servicegroup", group.Name, "member", member.Value(nsstatus.ColName))
+		}
+	}
+	gauges := []*gauge{lbvserverUp, listenerUp, memberUp}
+	drift, err := c.endpointDrift(groups)
+	if err != nil {
+		return nil, err
+	}
+	return append(gauges, drift...), nil
+}
+
+// choosePod chooses the controller pod and container again with the selector of the command
+func (c *collector) choosePod() error {
+	kClient, err := request.NewK8sClient(c.flags)
+	if err != nil {
+		return err
+	}
+	pod, cicContainer, _, err := kClient.ChoosePod(c.flags, c.selector)
+	if err != nil {
+		return err
+	}
+	validVer, mismatch, err := kubectl.ValidVersion(c.flags, pod, cicContainer)
+	if err != nil {
+		return err
+	}
+	if !validVer {
+		return errors.New(strings.TrimSpace(mismatch))
+	}
+	if pod.UID != c.pod.UID {
+		fmt.Printf("Polling the NetScaler entity status of pod %v/%v\n", pod.Namespace, pod.Name)
+	}
+	c.pod, c.cicContainer = pod, cicContainer
+	return nil
+}
+
+// endpointDrift compares the members of the service groups with the endpoints of the backend services of the ingresses
+func (c *collector) endpointDrift(groups []*nsstatus.ServiceGroup) ([]*gauge, error) {
+	members := newGauge("netscaler_servicegroup_members", "Number of members of the NetScaler service group of an ingress.")
+	endpoints := newGauge("netscaler_kubernetes_service_endpoints", "Number of endpoint addresses of the backend service of an ingress.")
+	drift := newGauge("netscaler_endpoint_drift", "Service group members minus Kubernetes endpoint addresses of the backend service of an ingress.")
+	// A new client is created for every poll so that EndpointSlices are not served from the cache of the previous poll
+	kClient, err := request.NewK8sClient(c.flags)
+	if err != nil {
+		return nil, err
+	}
+	ingresses := make(map[string][]networking.Ingress)
+	for _, group := range groups {
+		members.set(float64(len(group.Members)), "ingress", group.Ingress, "namespace", group.Namespace, "servicegroup", group.Name)
+		if group.Ingress == "" || group.Namespace == "" {
+			continue
+		}
+		if _, ok := ingresses[group.Namespace]; !ok {
+			ingresses[group.Namespace], err = kClient.GetIngressDefinitions(c.flags, group.Namespace)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, service := range request.BackendServices(ingresses[group.Namespace], group.Ingress, group.Port) {
+			count, err := kClient.GetNumEndpoints(c.flags, group.Namespace, service)
+			if err != nil {
+				return nil, err
+			}
+			addresses := 0
+			if count != nil {
+				addresses = *count
+			}
+			endpoints.set(float64(addresses), "namespace", group.Namespace, "service", service)
+			drift.set(float64(len(group.Members)-addresses), "ingress", group.Ingress, "namespace", group.Namespace, "servicegroup", group.Name, "service", service)
+		}
+	}
+	return []*gauge{members, endpoints, drift}, nil
+}
+
+// boolValue converts a state to a gauge value
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
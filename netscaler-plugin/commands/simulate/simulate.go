@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulate
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/bundle"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nitrosim"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// SimulateCmdFlag struct for cobra command arguments for simulate sub command
+type SimulateCmdFlag struct {
+	listen  *string
+	fixture *string
+	conf    *string
+}
+
+// initSimulateCmdFlag initializes struct SimulateCmdFlag based on json based constants
+func initSimulateCmdFlag(flag *SimulateCmdFlag, cmd *cobra.Command) {
+	flag.listen = util.AddFlagStringP(cmd, []byte(constant.ListenFlag))
+	flag.fixture = util.AddFlagStringP(cmd, []byte(constant.FixtureFlag))
+	flag.conf = util.AddFlagStringP(cmd, []byte(constant.ConfDumpFlag))
+}
+
+// CreateCommand creates the cobra commands for simulate subcommand
+func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
+	simulateCmdFlag := SimulateCmdFlag{}
+	cmd := &cobra.Command{
+		Use:    "simulate",
+		Short:  "Run a local NITRO API simulator seeded from a fixture or a saved conf output",
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			util.PrintError(simulate(simulateCmdFlag))
+			return nil
+		},
+	}
+	initSimulateCmdFlag(&simulateCmdFlag, cmd)
+	return cmd
+}
+
+// simulate seeds the simulator and serves the NITRO API until interrupted
+func simulate(simulateCmdFlag SimulateCmdFlag) error {
+	username, password := os.Getenv(constant.NitroUserEnv), os.Getenv(constant.NitroPasswordEnv)
+	if username == "" {
+		username, password = constant.NitroDefaultUser, constant.NitroDefaultUser
+	}
+	sim := nitrosim.New(username, password)
+	if len(*simulateCmdFlag.fixture) > 0 {
+		if err := sim.LoadFixture(*simulateCmdFlag.fixture); err != nil {
+			return err
+		}
+	}
+	switch {
+	case len(*simulateCmdFlag.conf) > 0:
+		content, err := os.ReadFile(*simulateCmdFlag.conf)
+		if err != nil {
+			return err
+		}
+		sim.LoadConf(string(content))
+	case bundle.Enabled():
+		content, err := bundle.ReadNetScalerFile(constant.BundleConfFile)
+		if err != nil {
+			return err
+		}
+		sim.LoadConf(content)
+	case len(*simulateCmdFlag.fixture) == 0:
+		return errors.New("seed the simulator with --fixture, --conf or --from-bundle")
+	}
+	fmt.Printf("NITRO simulator listening on http://%v (user %v)\n", *simulateCmdFlag.listen, username)
+	return http.ListenAndServe(*simulateCmdFlag.listen, sim)
+}
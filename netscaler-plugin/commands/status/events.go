@@ -0,0 +1,198 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// eventObject identifies a Kubernetes object whose events are displayed
+type eventObject struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// eventSummary aggregates the events of one object with the same reason
+type eventSummary struct {
+	Reason   string    `json:"reason"`
+	Count    int32     `json:"count"`
+	LastSeen time.Time `json:"lastSeen"`
+	Message  string    `json:"message"`
+}
+
+// objectEvents groups the deduplicated events of one object
+type objectEvents struct {
+	Object eventObject    `json:"object"`
+	Events []eventSummary `json:"events"`
+}
+
+// relatedObjects lists the ingresses of the ingress controller matching ing (all its ingresses if empty), their
+// backend services, the pods behind these services and the ingress controller pod
+func relatedObjects(kClient request.K8sClient, flags *genericclioptions.ConfigFlags, pod apiv1.Pod, ing string) ([]eventObject, error) {
+	objects := []eventObject{{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name}}
+	seen := map[eventObject]bool{objects[0]: true}
+	add := func(obj eventObject) {
+		if !seen[obj] {
+			seen[obj] = true
+			objects = append(objects, obj)
+		}
+	}
+	ingresses, err := kClient.GetControllerIngresses(flags, pod, "")
+	if err != nil {
+		return objects, err
+	}
+	for _, ingress := range ingresses {
+		if ing != "" && ingress.Name != ing {
+			continue
+		}
+		add(eventObject{Kind: "Ingress", Namespace: ingress.Namespace, Name: ingress.Name})
+		for _, backend := range request.IngressBackends(ingress) {
+			add(eventObject{Kind: "Service", Namespace: ingress.Namespace, Name: backend.Name})
+			slices, err := kClient.GetEndpointSlicesByName(flags, ingress.Namespace, backend.Name)
+			if err != nil {
+				return objects, err
+			}
+			for _, slice := range slices {
+				for _, ep := range slice.Endpoints {
+					if ep.TargetRef != nil && ep.TargetRef.Kind == "Pod" {
+						add(eventObject{Kind: "Pod", Namespace: ep.TargetRef.Namespace, Name: ep.TargetRef.Name})
+					}
+				}
+			}
+		}
+	}
+	return objects, nil
+}
+
+// groupEvents keeps the events of the given objects, grouped by object and deduplicated by reason
+func groupEvents(objects []eventObject, events []apiv1.Event) []objectEvents {
+	byObject := make(map[eventObject]map[string]*eventSummary)
+	order := make(map[eventObject][]string)
+	for _, ev := range events {
+		obj := eventObject{Kind: ev.InvolvedObject.Kind, Namespace: ev.InvolvedObject.Namespace, Name: ev.InvolvedObject.Name}
+		if _, ok := byObject[obj]; !ok {
+			byObject[obj] = make(map[string]*eventSummary)
+		}
+		count := ev.Count
+		if ev.Series != nil && ev.Series.Count > count {
+			count = ev.Series.Count
+		}
+		if count == 0 {
+			count = 1
+		}
+		lastSeen := ev.LastTimestamp.Time
+		if lastSeen.IsZero() {
+			lastSeen = ev.EventTime.Time
+		}
+		summary, ok := byObject[obj][ev.Reason]
+		if !ok {
+			summary = &eventSummary{Reason: ev.Reason}
+			byObject[obj][ev.Reason] = summary
+			order[obj] = append(order[obj], ev.Reason)
+		}
+		summary.Count += count
+		if !lastSeen.Before(summary.LastSeen) {
+			summary.LastSeen = lastSeen
+			summary.Message = strings.TrimSpace(ev.Message)
+		}
+	}
+	groups := make([]objectEvents, 0)
+	for _, obj := range objects {
+		reasons, ok := order[obj]
+		if !ok {
+			continue
+		}
+		group := objectEvents{Object: obj}
+		for _, reason := range reasons {
+			group.Events = append(group.Events, *byObject[obj][reason])
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// statusDocument is the JSON output of status with --events, the status and the events in a single document
+type statusDocument struct {
+	Status interface{}    `json:"status"`
+	Events []objectEvents `json:"events"`
+}
+
+// eventGroups returns the Warning events related to the ingresses and the ingress controller pod. The events are
+// only listed in the namespaces of these objects.
+func eventGroups(kClient request.K8sClient, flags *genericclioptions.ConfigFlags, pod apiv1.Pod, ing string) ([]objectEvents, error) {
+	objects, err := relatedObjects(kClient, flags, pod, ing)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := make([]string, 0)
+	for _, obj := range objects {
+		if !util.ContainsString(namespaces, obj.Namespace) {
+			namespaces = append(namespaces, obj.Namespace)
+		}
+	}
+	events := make([]apiv1.Event, 0)
+	for _, namespace := range namespaces {
+		nsEvents, err := kClient.GetWarningEvents(flags, namespace)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, nsEvents...)
+	}
+	return groupEvents(objects, events), nil
+}
+
+// printStatusJSON prints the status and the events as a single JSON document
+func printStatusJSON(out io.Writer, status interface{}, groups []objectEvents) error {
+	op, err := json.MarshalIndent(statusDocument{Status: status, Events: groups}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, string(op))
+	return nil
+}
+
+// printEvents displays the Warning events related to the ingresses and the ingress controller pod
+func printEvents(out io.Writer, groups []objectEvents) error {
+	if len(groups) == 0 {
+		fmt.Fprintln(out, "\nNo Warning events found for the ingresses, their services and pods or the ingress controller pod")
+		return nil
+	}
+	fmt.Fprintln(out, "\nWarning events:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, group := range groups {
+		fmt.Fprintf(w, "%v %v/%v\n", group.Object.Kind, group.Object.Namespace, group.Object.Name)
+		fmt.Fprintln(w, "  REASON\tCOUNT\tLAST SEEN\tMESSAGE")
+		for _, summary := range group.Events {
+			lastSeen := "--"
+			if !summary.LastSeen.IsZero() {
+				lastSeen = duration.HumanDuration(time.Since(summary.LastSeen))
+			}
+			fmt.Fprintf(w, "  %v\t%v\t%v\t%v\n", summary.Reason, summary.Count, lastSeen, strings.ReplaceAll(summary.Message, "\n", " "))
+		}
+	}
+	return w.Flush()
+}
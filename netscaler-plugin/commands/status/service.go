@@ -0,0 +1,314 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/bundle"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/kubectl"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nitro"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nsconf"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nsdata"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// ServiceStatus is the NetScaler configuration of a Service of type LoadBalancer
+type ServiceStatus struct {
+	Namespace   string       `json:"namespace"`
+	Name        string       `json:"name"`
+	Type        string       `json:"type"`
+	ExternalIPs []string     `json:"externalIPs"`
+	Populated   bool         `json:"externalIPPopulated"`
+	Ports       []PortStatus `json:"ports"`
+	Issues      []string     `json:"issues"`
+}
+
+// PortStatus is the NetScaler virtual server and service group members of a Service port
+type PortStatus struct {
+	Port         int32          `json:"port"`
+	Protocol     string         `json:"protocol"`
+	NodePort     int32          `json:"nodePort,omitempty"`
+	TargetPort   string         `json:"targetPort"`
+	VServer      string         `json:"vserver"`
+	VIP          string         `json:"vip"`
+	State        string         `json:"state"`
+	ServiceGroup string         `json:"servicegroup"`
+	Members      []MemberStatus `json:"members"`
+}
+
+// MemberStatus is a member of the service group of a Service port
+type MemberStatus struct {
+	Address string `json:"address"`
+	Port    string `json:"port"`
+	State   string `json:"state"`
+}
+
+// serviceStatus displays the NetScaler virtual servers and members of the Service given with --service,
+// or of all the Services of type LoadBalancer of the namespace
+func serviceStatus(out io.Writer, kClient request.K8sClient, flags *genericclioptions.ConfigFlags, statusCmdFlag StatusCmdFlag) error {
+	services, err := lbServices(kClient, flags, statusCmdFlag)
+	if err != nil {
+		return err
+	}
+	var pod apiv1.Pod
+	var cicContainer string
+	prefix := *statusCmdFlag.prefix
+	if nitro.URL == "" {
+		pod, cicContainer, _, err = kClient.ChoosePod(flags, *statusCmdFlag.podSelector)
+		if err != nil {
+			return err
+		}
+		if prefix == "" {
+			prefix = request.ControllerPrefix(pod)
+		}
+		if !bundle.Enabled() {
+			validVer, mismatch, err := kubectl.ValidVersion(flags, pod, cicContainer)
+			if err != nil {
+				return util.CmdError(err)
+			}
+			if !validVer {
+				return errors.New(strings.TrimSpace(mismatch))
+			}
+		}
+	}
+	if prefix == "" {
+		prefix = constant.DefaultPrefix
+	}
+	snapshot, err := nsdata.Load(flags, &pod, cicContainer, "csvserver", "lbvserver", "lbvserver_servicegroup_binding", "servicegroup_servicegroupmember_binding")
+	if err != nil {
+		return err
+	}
+	statuses := make([]ServiceStatus, 0, len(services))
+	for _, svc := range services {
+		statuses = append(statuses, inspectService(svc, prefix, snapshot))
+	}
+	if strings.EqualFold(*statusCmdFlag.output, "json") {
+		op, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(op))
+		return nil
+	}
+	if len(statuses) == 0 {
+		fmt.Fprintln(out, "No Service of type LoadBalancer found")
+		return nil
+	}
+	printServiceStatus(out, statuses)
+	return nil
+}
+
+// lbServices resolves the Service given with --service, otherwise lists the Services of type LoadBalancer
+func lbServices(kClient request.K8sClient, flags *genericclioptions.ConfigFlags, statusCmdFlag StatusCmdFlag) ([]apiv1.Service, error) {
+	namespace := *statusCmdFlag.svcNamespace
+	if *statusCmdFlag.service != "" {
+		svc, err := kClient.GetServiceByName(flags, namespace, *statusCmdFlag.service, nil)
+		if err != nil {
+			return nil, err
+		}
+		return []apiv1.Service{svc}, nil
+	}
+	if namespace == "" {
+		ns, err := util.GetNamespace(flags)
+		if err != nil {
+			return nil, err
+		}
+		namespace = ns
+	}
+	services, err := kClient.GetNamespaceServices(flags, namespace)
+	if err != nil {
+		return nil, err
+	}
+	lbServices := make([]apiv1.Service, 0)
+	for _, svc := range services {
+		if svc.Spec.Type == apiv1.ServiceTypeLoadBalancer {
+			lbServices = append(lbServices, svc)
+		}
+	}
+	return lbServices, nil
+}
+
+// inspectService finds the virtual server of every port of the Service, on the external IP of the Service
+// or, while it is not populated, by the vserver name the ingress controller generates for the Service port
+func inspectService(svc apiv1.Service, prefix string, snapshot nsdata.Snapshot) ServiceStatus {
+	status := ServiceStatus{Namespace: svc.Namespace, Name: svc.Name, Type: string(svc.Spec.Type), ExternalIPs: make([]string, 0), Ports: make([]PortStatus, 0), Issues: make([]string, 0)}
+	for _, lb := range svc.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			status.ExternalIPs = append(status.ExternalIPs, lb.IP)
+		} else if lb.Hostname != "" {
+			status.ExternalIPs = append(status.ExternalIPs, lb.Hostname)
+		}
+	}
+	status.Populated = len(status.ExternalIPs) > 0
+	if svc.Spec.Type != apiv1.ServiceTypeLoadBalancer {
+		status.Issues = append(status.Issues, "not of type LoadBalancer")
+	} else if !status.Populated {
+		status.Issues = append(status.Issues, "external IP not populated")
+	}
+	for _, svcPort := range svc.Spec.Ports {
+		port := PortStatus{Port: svcPort.Port, Protocol: string(svcPort.Protocol), NodePort: svcPort.NodePort, TargetPort: svcPort.TargetPort.String(), State: nsdata.StateUnknown, Members: make([]MemberStatus, 0)}
+		resource, vserver := findVServer(vserverName(prefix, svc, svcPort.Port), svcPort.Port, status.ExternalIPs, snapshot)
+		if vserver == nil {
+			status.Issues = append(status.Issues, fmt.Sprintf("no NetScaler vserver for port %v", svcPort.Port))
+			status.Ports = append(status.Ports, port)
+			continue
+		}
+		port.VServer, port.VIP = vserver.String("name"), vserver.String("ipv46")
+		port.State = snapshot.State(resource, port.VServer)
+		if status.Populated && !util.ContainsString(status.ExternalIPs, port.VIP) {
+			status.Issues = append(status.Issues, fmt.Sprintf("VIP %v of port %v is not the external IP", port.VIP, svcPort.Port))
+		}
+		lbvserver := port.VServer
+		if resource != "lbvserver" {
+			if lb := findByName(snapshot.Get("lbvserver"), vserverName(prefix, svc, svcPort.Port)); lb != nil {
+				lbvserver = lb.String("name")
+			}
+		}
+		for _, binding := range snapshot.Get("lbvserver_servicegroup_binding") {
+			if binding.String("name") == lbvserver {
+				port.ServiceGroup = binding.String("servicegroupname")
+				break
+			}
+		}
+		for _, member := range snapshot.Get("servicegroup_servicegroupmember_binding") {
+			if port.ServiceGroup == "" || member.String("servicegroupname") != port.ServiceGroup {
+				continue
+			}
+			port.Members = append(port.Members, MemberStatus{Address: member.String("ip"), Port: member.String("port"), State: memberState(member, snapshot.Live)})
+		}
+		if len(port.Members) == 0 {
+			status.Issues = append(status.Issues, fmt.Sprintf("no service group member for port %v", svcPort.Port))
+		}
+		status.Ports = append(status.Ports, port)
+	}
+	return status
+}
+
+// findVServer returns the content switching or load balancing vserver listening on an external IP and port,
+// otherwise the vserver of the given name
+func findVServer(name string, port int32, externalIPs []string, snapshot nsdata.Snapshot) (string, nsconf.Object) {
+	for _, resource := range []string{"csvserver", "lbvserver"} {
+		for _, vserver := range snapshot.Get(resource) {
+			if util.ContainsString(externalIPs, vserver.String("ipv46")) && vserver.String("port") == strconv.Itoa(int(port)) {
+				return resource, vserver
+			}
+		}
+	}
+	for _, resource := range []string{"csvserver", "lbvserver"} {
+		if vserver := findByName(snapshot.Get(resource), name); vserver != nil {
+			return resource, vserver
+		}
+	}
+	return "", nil
+}
+
+// vserverName returns the name of the vserver the ingress controller creates for a port of a Service of type
+// LoadBalancer, such as k8s-frontend_80_default_svc
+func vserverName(prefix string, svc apiv1.Service, port int32) string {
+	return fmt.Sprintf("%v-%v_%v_%v_svc", prefix, svc.Name, port, svc.Namespace)
+}
+
+// findByName returns the vserver of the given name, NetScaler entity names are case insensitive
+func findByName(vservers []nsconf.Object, name string) nsconf.Object {
+	for _, vserver := range vservers {
+		if strings.EqualFold(vserver.String("name"), name) {
+			return vserver
+		}
+	}
+	return nil
+}
+
+// memberState returns the state of a service group member, only known over NITRO unless it is disabled
+func memberState(member nsconf.Object, live bool) string {
+	if live && member.String("svrstate") != "" {
+		return member.String("svrstate")
+	}
+	if strings.EqualFold(member.String("state"), "DISABLED") {
+		return "DISABLED"
+	}
+	return nsdata.StateUnknown
+}
+
+// printServiceStatus prints the vservers of the Service ports followed by the service group members
+func printServiceStatus(out io.Writer, statuses []ServiceStatus) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tSERVICE\tEXTERNAL IP\tPORT\tNODEPORT\tVSERVER\tVIP\tSTATE\tSERVICEGROUP\tMEMBERS UP")
+	for _, status := range statuses {
+		externalIP := "<pending>"
+		if status.Populated {
+			externalIP = strings.Join(status.ExternalIPs, ",")
+		}
+		for _, port := range status.Ports {
+			up, known := 0, false
+			for _, member := range port.Members {
+				if strings.EqualFold(member.State, "UP") {
+					up++
+				}
+				known = known || member.State != nsdata.StateUnknown
+			}
+			membersUp := fmt.Sprintf("%v/%v", up, len(port.Members))
+			if !known && len(port.Members) > 0 {
+				membersUp = fmt.Sprintf("--/%v", len(port.Members))
+			}
+			nodePort := "--"
+			if port.NodePort != 0 {
+				nodePort = strconv.Itoa(int(port.NodePort))
+			}
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v/%v\t%v\t%v\t%v\t%v\t%v\t%v\n", status.Namespace, status.Name, externalIP, port.Port, port.Protocol, nodePort,
+				orNone(port.VServer), orNone(port.VIP), port.State, orNone(port.ServiceGroup), membersUp)
+		}
+	}
+	w.Flush()
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tSERVICE\tPORT\tSERVICEGROUP\tMEMBER\tSTATE")
+	for _, status := range statuses {
+		for _, port := range status.Ports {
+			for _, member := range port.Members {
+				fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v:%v\t%v\n", status.Namespace, status.Name, port.Port, port.ServiceGroup, member.Address, member.Port, member.State)
+			}
+		}
+	}
+	w.Flush()
+	issues := false
+	for _, status := range statuses {
+		for _, issue := range status.Issues {
+			if !issues {
+				fmt.Fprintln(out, "\nIssues:")
+				issues = true
+			}
+			fmt.Fprintf(out, "  %v/%v: %v\n", status.Namespace, status.Name, issue)
+		}
+	}
+}
+
+// orNone prints -- for empty cells
+func orNone(value string) string {
+	if value == "" {
+		return "--"
+	}
+	return value
+}
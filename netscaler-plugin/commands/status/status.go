@@ -14,40 +14,52 @@ limitations under the License.
 package status
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"io"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/bundle"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/cluster"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/kubectl"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nsstatus"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
 )
 
 // StatusCmdFlag struct for cobra command arguments for status sub command
 type StatusCmdFlag struct {
-	pod        *string
-	deployment *string
-	selector   *string
-	output     *string
-	ing        *string
-	prefix     *string
-	verbosity  *bool
+	podSelector  *request.PodSelector
+	output       *string
+	ing          *string
+	prefix       *string
+	verbosity    *bool
+	events       *bool
+	service      *string
+	allServices  *bool
+	svcNamespace *string
+	clusters     *cluster.Flags
 }
 
 // initStatusCmdFlag initializes struct StatusCmdFlag based on json based constants
 func initStatusCmdFlag(flag *StatusCmdFlag, cmd *cobra.Command) {
-	flag.pod = util.AddFlagStringP(cmd, []byte(constant.PodFlag))
-	flag.deployment = util.AddFlagStringP(cmd, []byte(constant.DeployFlag))
-	flag.selector = util.AddFlagStringP(cmd, []byte(constant.SelectorFlag))
+	flag.podSelector = request.AddPodSelectorFlags(cmd)
 	flag.output = util.AddFlagStringP(cmd, []byte(constant.OutputFlag))
 	flag.ing = util.AddFlagStringP(cmd, []byte(constant.IngressFlag))
 	flag.prefix = util.AddFlagStringP(cmd, []byte(constant.PrefixFlag))
 	flag.verbosity = util.AddFlagBoolP(cmd, []byte(constant.VerboseFlag))
+	flag.events = util.AddFlagBoolP(cmd, []byte(constant.EventsFlag))
+	flag.service = util.AddFlagStringP(cmd, []byte(constant.ServiceFlag))
+	flag.allServices = util.AddFlagBoolP(cmd, []byte(constant.AllServicesFlag))
+	flag.svcNamespace = util.AddFlagStringP(cmd, []byte(constant.ServiceNSFlag))
+	flag.clusters = cluster.AddFlags(cmd)
 }
 
 // CreateCommand creates the cobra commands for status subcommand
@@ -57,7 +69,12 @@ func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
 		Use:   "status",
 		Short: "Display the status (up/down/active...) of NetScaler entities for provided prefix input (Default value of the prefix is k8s)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			util.PrintError(status(flags, statusCmdFlag))
+			// The --verbose option of status shadows the global one
+			util.Verbose = util.Verbose || *statusCmdFlag.verbosity
+			cluster.Exit(statusCmdFlag.clusters, cluster.Run(flags, statusCmdFlag.clusters, strings.EqualFold(*statusCmdFlag.output, "json"),
+				func(out io.Writer, flags *genericclioptions.ConfigFlags, context string) error {
+					return status(out, flags, statusCmdFlag)
+				}))
 			return nil
 		},
 	}
@@ -66,25 +83,31 @@ func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
 }
 
 // status receives user inputs and filters kubernetes object and runs plugin file with status sub in cic container
-func status(flags *genericclioptions.ConfigFlags, statusCmdFlag StatusCmdFlag) error {
+func status(out io.Writer, flags *genericclioptions.ConfigFlags, statusCmdFlag StatusCmdFlag) error {
 	/*********************************************************
 	 * kClient cannot be initialized in main to be in sync   *
 	 * with Cobra behaviour for defered flags init post RunE *
 	 *********************************************************/
 	kClient, err := request.NewK8sClient(flags)
 	if err != nil {
-		fmt.Println("unable to init K8s Client: " + err.Error())
-		os.Exit(1)
-
+		return errors.New("unable to init K8s Client: " + err.Error())
+	}
+	if len(*statusCmdFlag.service) > 0 || *statusCmdFlag.allServices {
+		return serviceStatus(out, kClient, flags, statusCmdFlag)
 	}
-	pod, cicContainer, _, err := kClient.ChoosePod(flags, *statusCmdFlag.pod, *statusCmdFlag.deployment, *statusCmdFlag.selector)
+	pod, cicContainer, _, err := kClient.ChoosePod(flags, *statusCmdFlag.podSelector)
 	if err != nil {
 		return err
 	}
+	if bundle.Enabled() {
+		return bundleStatus(out, kClient, flags, pod, statusCmdFlag)
+	}
 	validVer, mismatch, err := kubectl.ValidVersion(flags, pod, cicContainer)
-	util.CmdErrorHandling(err)
+	if err != nil {
+		return util.CmdError(err)
+	}
 	if !validVer {
-		fmt.Print(mismatch)
+		fmt.Fprint(out, mismatch)
 		return nil
 	}
 	var flagCommand []string
@@ -110,7 +133,130 @@ func status(flags *genericclioptions.ConfigFlags, statusCmdFlag StatusCmdFlag) e
 		flagCommand = append(flagCommand, "-v")
 	}
 	cicStatus, err := kubectl.PodExecString(flags, &pod, flagCommand)
-	util.CmdErrorHandling(err)
-	fmt.Println(strings.TrimRight(strings.Trim(cicStatus, " \n"), " \n\t"))
+	if err != nil {
+		return util.CmdError(err)
+	}
+	cicStatus = strings.TrimRight(strings.Trim(cicStatus, " \n"), " \n\t")
+	if *statusCmdFlag.events && strings.EqualFold(*statusCmdFlag.output, "json") {
+		groups, err := eventGroups(kClient, flags, pod, *statusCmdFlag.ing)
+		if err != nil {
+			return err
+		}
+		var status interface{} = cicStatus
+		if json.Valid([]byte(cicStatus)) {
+			status = json.RawMessage(cicStatus)
+		}
+		return printStatusJSON(out, status, groups)
+	}
+	fmt.Fprintln(out, cicStatus)
+	if lenApp > 0 && !strings.EqualFold(*statusCmdFlag.output, "json") {
+		ingressClassNotice(out, kClient, flags, pod, *statusCmdFlag.ing)
+	}
+	if !strings.EqualFold(*statusCmdFlag.output, "json") {
+		leaderNotice(out, kClient, flags, pod)
+	}
+	if *statusCmdFlag.events {
+		groups, err := eventGroups(kClient, flags, pod, *statusCmdFlag.ing)
+		if err != nil {
+			return err
+		}
+		return printEvents(out, groups)
+	}
+	return nil
+}
+
+// bundleStatus displays the NetScaler entity status recorded in the support bundle
+func bundleStatus(out io.Writer, kClient request.K8sClient, flags *genericclioptions.ConfigFlags, pod apiv1.Pod, statusCmdFlag StatusCmdFlag) error {
+	op, err := bundle.ReadNetScalerFile(constant.BundleStatusFile)
+	if err != nil {
+		return err
+	}
+	table := nsstatus.Parse(op)
+	if len(*statusCmdFlag.ing) > 0 {
+		table = table.FilterIngress(*statusCmdFlag.ing)
+	}
+	if strings.EqualFold(*statusCmdFlag.output, "json") {
+		if *statusCmdFlag.events {
+			groups, err := eventGroups(kClient, flags, pod, *statusCmdFlag.ing)
+			if err != nil {
+				return err
+			}
+			return printStatusJSON(out, table.JSON(), groups)
+		}
+		jsonOp, err := json.MarshalIndent(table.JSON(), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(jsonOp))
+		return nil
+	}
+	fmt.Fprintln(out, table.String())
+	if len(*statusCmdFlag.ing) > 0 {
+		ingressClassNotice(out, kClient, flags, pod, *statusCmdFlag.ing)
+	}
+	leaderNotice(out, kClient, flags, pod)
+	if *statusCmdFlag.events {
+		groups, err := eventGroups(kClient, flags, pod, *statusCmdFlag.ing)
+		if err != nil {
+			return err
+		}
+		return printEvents(out, groups)
+	}
 	return nil
 }
+
+// leaderNotice tells whether the pod holds the leader-election lock of the ingress controller or is a standby of
+// the replica programming NetScaler. Nothing is printed when no lock is found or the locks cannot be read.
+func leaderNotice(out io.Writer, kClient request.K8sClient, flags *genericclioptions.ConfigFlags, pod apiv1.Pod) {
+	leader, ok, err := kClient.PodLeader(flags, pod)
+	if err != nil || !ok {
+		return
+	}
+	if leader.HeldBy(pod) && leader.Expired {
+		fmt.Fprintf(out, "\nLeader election: pod %v/%v last held %v, no replica renewed it\n", pod.Namespace, pod.Name, leader)
+		return
+	}
+	if leader.HeldBy(pod) {
+		fmt.Fprintf(out, "\nLeader election: pod %v/%v is the leader, it holds %v\n", pod.Namespace, pod.Name, leader)
+		return
+	}
+	fmt.Fprintf(out, "\nLeader election: pod %v/%v is a standby, %v holds %v. The status of a standby may not reflect NetScaler\n",
+		pod.Namespace, pod.Name, leader.Identity, leader)
+}
+
+// ingressClassNotice explains an empty status when the ingress given with -i is of a class the selected
+// ingress controller does not handle. The Ingresses are looked up in the namespace of the pod and in the namespace
+// given with -n. Nothing is printed when the Ingresses or IngressClasses cannot be read, such as with RBAC
+// permissions limited to the namespace.
+func ingressClassNotice(out io.Writer, kClient request.K8sClient, flags *genericclioptions.ConfigFlags, pod apiv1.Pod, ing string) {
+	ingressClasses, err := kClient.GetIngressClasses(flags)
+	if err != nil {
+		return
+	}
+	namespaces := []string{pod.Namespace}
+	if namespace, err := util.GetNamespace(flags); err == nil && namespace != pod.Namespace {
+		namespaces = append(namespaces, namespace)
+	}
+	classes := request.ControllerClasses(pod)
+	handled := "Ingresses without class and of the NetScaler IngressClasses"
+	if len(classes) > 0 {
+		handled = "the ingress classes " + strings.Join(classes, ", ")
+	}
+	for _, namespace := range namespaces {
+		ingresses, err := kClient.GetIngressDefinitions(flags, namespace)
+		if err != nil {
+			continue
+		}
+		for _, ingress := range ingresses {
+			if ingress.Name != ing || request.OwnsIngress(classes, ingressClasses, ingress) {
+				continue
+			}
+			class := request.IngressClassName(ingress)
+			if class == "" {
+				class = "none"
+			}
+			fmt.Fprintf(out, "\nIngress %v/%v of class %v is not handled by the ingress controller pod %v/%v, which handles %v\n",
+				ingress.Namespace, ingress.Name, class, pod.Namespace, pod.Name, handled)
+		}
+	}
+}
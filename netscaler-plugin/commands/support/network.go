@@ -0,0 +1,154 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"text/tabwriter"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/kubectl"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
+)
+
+// networkInfo saves the detected container network plugin, its configuration and custom resources, the kube-proxy
+// configuration and a summary of the routes NetScaler needs to reach the pods
+func networkInfo(out io.Writer, kClient request.K8sClient, flags *genericclioptions.ConfigFlags, pod apiv1.Pod, directory string, unMask bool) error {
+	network, err := kClient.GetNetwork(flags)
+	if err != nil {
+		return err
+	}
+	configMaps := network.ConfigMaps
+	if network.KubeProxyMode != "" {
+		configMaps = append(configMaps, "kube-system/"+constant.KubeProxy)
+	}
+	for _, configMap := range configMaps {
+		parts := strings.SplitN(configMap, "/", 2)
+		op, err := kubectl.ExecToString(flags, []string{"get", "configmap", parts[1], "-n", parts[0], "-o", "yaml"})
+		if err != nil {
+			fmt.Fprintln(out, "Error while getting ConfigMap "+configMap+": "+strings.TrimSpace(op))
+			continue
+		}
+		if err = kubectl.SaveFile(directory, "configmap_"+parts[0]+"_"+parts[1]+".txt", op, unMask); err != nil {
+			return err
+		}
+	}
+	for _, resource := range network.Resources {
+		// The custom resources are not installed by every version of the plugin
+		op, err := kubectl.ExecToString(flags, []string{"get", resource, "-A", "-o", "yaml"})
+		if err != nil {
+			continue
+		}
+		if err = kubectl.SaveFile(directory, resource+".txt", op, unMask); err != nil {
+			return err
+		}
+	}
+	ctrl, _ := request.ClassifyPod(pod)
+	var buf bytes.Buffer
+	cni := "not detected"
+	if network.CNI != "" {
+		cni = network.CNI + " (" + strings.Join(network.Workloads, ", ") + ")"
+	}
+	kubeProxyMode := network.KubeProxyMode
+	if kubeProxyMode == "" {
+		kubeProxyMode = "kube-proxy not deployed"
+	}
+	fmt.Fprintf(&buf, "Container network plugin: %v\n", cni)
+	fmt.Fprintf(&buf, "kube-proxy mode: %v\n", kubeProxyMode)
+	fmt.Fprintf(&buf, "Ingress controller: %v/%v (%v, NetScaler %v)\n\n", pod.Namespace, pod.Name, orNone(ctrl.Mode), orNone(ctrl.Target))
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tINTERNAL IP\tPOD CIDRS")
+	for _, node := range network.Nodes {
+		fmt.Fprintf(w, "%v\t%v\t%v\n", node.Name, orNone(node.InternalIP), orNone(strings.Join(node.PodCIDRs, ",")))
+	}
+	w.Flush()
+	fmt.Fprintln(&buf)
+	for _, line := range routeSummary(network, ctrl.Mode, request.RouteAddition(pod), unMask) {
+		fmt.Fprintln(&buf, line)
+	}
+	return kubectl.SaveFile(directory, constant.NetworkFile, buf.String(), unMask)
+}
+
+// routeSummary tells whether NetScaler needs routes to the pod networks of the nodes, followed by the NetScaler
+// commands adding them when the ingress controller does not. The IP addresses of the commands would be masked in
+// the file, the routes are then counted and described by node and prefix length.
+func routeSummary(network request.Network, mode string, routeAddition bool, unMask bool) []string {
+	if mode == constant.ModeSidecar || mode == constant.ModeCPX {
+		return []string{"NetScaler CPX runs in the pod network, no route to the pods is needed on NetScaler."}
+	}
+	if network.Routable {
+		return []string{"Pods get addresses of the node network with " + network.CNI + ", no route to the pods is needed on NetScaler VPX/MPX."}
+	}
+	lines := []string{"NetScaler VPX/MPX needs a route to the pod network of each node, through the node address."}
+	if routeAddition {
+		lines = append(lines, "The route-addition feature ("+constant.NodeWatchArg+") is enabled, the ingress controller adds these routes when NetScaler is in the subnet of the nodes.")
+	} else {
+		lines = append(lines, "The route-addition feature ("+constant.NodeWatchArg+") is disabled. Enable it when NetScaler is in the subnet of the nodes,"+
+			" add the routes on NetScaler, or use the NetScaler node controller when NetScaler is in another subnet.")
+	}
+	if network.CNI == "Calico" || network.CNI == "Canal" {
+		lines = append(lines, network.CNI+" allocates pod addresses from the blocks of its IP pools, which may differ from the PodCIDRs of the nodes.")
+	}
+	missing := make([]string, 0)
+	routes, maskedRoutes := make([]string, 0), make([]string, 0)
+	ipv4Routes, ipv6Routes := 0, 0
+	for _, node := range network.Nodes {
+		if len(node.PodCIDRs) == 0 || node.InternalIP == "" {
+			missing = append(missing, node.Name)
+			continue
+		}
+		for _, podCIDR := range node.PodCIDRs {
+			_, ipNet, err := net.ParseCIDR(podCIDR)
+			if err != nil {
+				continue
+			}
+			prefixLength, _ := ipNet.Mask.Size()
+			// NetScaler adds IPv6 routes with add route6
+			if ipNet.IP.To4() == nil {
+				ipv6Routes++
+				maskedRoutes = append(maskedRoutes, fmt.Sprintf("add route6: IPv6 /%v pod network of node %v", prefixLength, node.Name))
+				continue
+			}
+			ipv4Routes++
+			routes = append(routes, fmt.Sprintf("add route %v %v %v", ipNet.IP, net.IP(ipNet.Mask), node.InternalIP))
+			maskedRoutes = append(maskedRoutes, fmt.Sprintf("add route: IPv4 /%v pod network of node %v", prefixLength, node.Name))
+		}
+	}
+	if len(missing) > 0 {
+		lines = append(lines, "The routes of the nodes "+strings.Join(missing, ", ")+" cannot be derived, they have no PodCIDR or internal IP.")
+	}
+	if routeAddition {
+		return lines
+	}
+	if unMask {
+		if len(routes) > 0 {
+			lines = append(lines, "")
+			lines = append(lines, routes...)
+		}
+		return lines
+	}
+	if len(maskedRoutes) == 0 {
+		return lines
+	}
+	lines = append(lines, "", fmt.Sprintf("%v IPv4 routes (add route) and %v IPv6 routes (add route6) are needed. The IP addresses are hidden,"+
+		" collect the support bundle with --unhideIP and --encrypt-to to list the commands.", ipv4Routes, ipv6Routes))
+	return append(lines, maskedRoutes...)
+}
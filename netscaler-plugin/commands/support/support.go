@@ -14,14 +14,21 @@ limitations under the License.
 package support
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/bundle"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/cluster"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/kubectl"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
@@ -30,29 +37,37 @@ import (
 
 // Constant map for kubernetes objects to query using kubectl get
 func getCMD() []string {
-	return []string{"pods", "deployment", "svc", "ing", "events", "nodes"}
+	return []string{"pods", "deployment", "svc", "ing", "endpointslices", "events", "nodes"}
 }
 
 // SupportCmdFlag struct for cobra command arguments for support sub command
 type SupportCmdFlag struct {
-	pod              *string
-	deployment       *string
-	selector         *string
+	podSelector      *request.PodSelector
 	dir              *string
 	appns            *string
 	skipNsBundleFlag *bool
 	unMask           *bool
+	prefix           *string
+	encryptTo        *string
+	since            *string
+	tail             *int
+	maxLogBytes      *int
+	clusters         *cluster.Flags
 }
 
 // initSupportCmdFlag initializes struct SupportCmdFlag based on json based constants
 func initSupportCmdFlag(flag *SupportCmdFlag, cmd *cobra.Command) {
-	flag.pod = util.AddFlagStringP(cmd, []byte(constant.PodFlag))
-	flag.deployment = util.AddFlagStringP(cmd, []byte(constant.DeployFlag))
-	flag.selector = util.AddFlagStringP(cmd, []byte(constant.SelectorFlag))
+	flag.podSelector = request.AddPodSelectorFlags(cmd)
 	flag.dir = util.AddFlagStringP(cmd, []byte(constant.DirFlag))
 	flag.appns = util.AddFlagStringP(cmd, []byte(constant.AppNSFlag))
 	flag.skipNsBundleFlag = util.AddFlagBoolP(cmd, []byte(constant.SkipNSBundleFlag))
 	flag.unMask = util.AddFlagBoolP(cmd, []byte(constant.UnmaskFlag))
+	flag.prefix = util.AddFlagStringP(cmd, []byte(constant.StsPrefixFlag))
+	flag.encryptTo = util.AddFlagStringP(cmd, []byte(constant.EncryptToFlag))
+	flag.since = util.AddFlagStringP(cmd, []byte(constant.LogsSinceFlag))
+	flag.tail = util.AddFlagIntP(cmd, []byte(constant.LogsTailFlag))
+	flag.maxLogBytes = util.AddFlagIntP(cmd, []byte(constant.MaxLogBytesFlag))
+	flag.clusters = cluster.AddFlags(cmd)
 }
 
 // Constant map for kubernetes objects to query using kubectl describe
@@ -77,43 +92,140 @@ func kubeExtractInfo(flags *genericclioptions.ConfigFlags, ns string, directory
 	return nil
 }
 
-// kubeGetLogs runs kubectl get log for running/dead cic container
-func kubeGetLogs(flags *genericclioptions.ConfigFlags, podName string, ns string, cicContainer string, directory string, unMask bool) error {
-	var logCic, shutLogCic []string
-	podName = "pod/" + podName
-	logCic = []string{"logs", podName, cicContainer}
-	shutLogCic = []string{"logs", "-p", podName, cicContainer}
-	err := kubectl.RunCmdSaveFile(flags, ns, []string{"get", podName, "-o", "yaml"}, true, directory, unMask)
+// kubeGetLogs saves the definition of the controller pod and the current and previous logs of every init container
+// and container of the pod, each container in its own file
+func kubeGetLogs(out io.Writer, flags *genericclioptions.ConfigFlags, pod apiv1.Pod, limits kubectl.LogLimits, directory string, unMask bool) error {
+	err := kubectl.RunCmdSaveFile(flags, pod.Namespace, []string{"get", "pod/" + pod.Name, "-o", "yaml"}, true, directory, unMask)
 	if err != nil {
-		fmt.Println("Error while getting Deployments for running CIC")
+		fmt.Fprintln(out, "Error while getting Deployments for running CIC")
 		return err
 	}
-	err = kubectl.RunCmdSaveFile(flags, ns, logCic, true, directory, unMask)
+	restarts := make(map[string]int32)
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		restarts[status.Name] = status.RestartCount
+	}
+	for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		truncated, err := kubectl.SaveContainerLogs(flags, &pod, container.Name, false, limits, directory, unMask)
+		if err != nil {
+			fmt.Fprintln(out, "Error while getting logs of container "+container.Name+": "+err.Error())
+		}
+		if truncated {
+			fmt.Fprintf(out, "Logs of container %v truncated to the last %v bytes\n", container.Name, limits.MaxBytes)
+		}
+		// Previous logs exist only when the container restarted
+		if restarts[container.Name] == 0 {
+			continue
+		}
+		truncated, err = kubectl.SaveContainerLogs(flags, &pod, container.Name, true, limits, directory, unMask)
+		if err != nil {
+			fmt.Fprintln(out, "Error while getting previous logs of container "+container.Name+": "+err.Error())
+		}
+		if truncated {
+			fmt.Fprintf(out, "Previous logs of container %v truncated to the last %v bytes\n", container.Name, limits.MaxBytes)
+		}
+	}
+	return nil
+}
+
+// netScalerInfo saves the NetScaler running configuration and entity status for offline analysis with --from-bundle
+func netScalerInfo(flags *genericclioptions.ConfigFlags, pod apiv1.Pod, cicContainer string, prefix string, directory string, unMask bool) error {
+	var flagCommand []string
+	if cicContainer == "" {
+		flagCommand = []string{"--", constant.PyCmd, constant.PluginFile, "-c"}
+	} else {
+		flagCommand = []string{"-c", cicContainer, "--", constant.PyCmd, constant.PluginFile, "-c"}
+	}
+	op, err := kubectl.PodExecString(flags, &pod, append(flagCommand, constant.ConfSub))
 	if err != nil {
-		fmt.Println("Error while getting logs for running CIC")
+		return err
 	}
-	err = kubectl.RunCmdSaveFile(flags, ns, shutLogCic, true, directory, unMask)
+	err = kubectl.SaveFile(directory, constant.BundleConfFile, op, unMask)
 	if err != nil {
-		fmt.Println("Error while getting logs for shutdown CIC")
+		return err
 	}
-	return nil
+	statusCommand := append(flagCommand, constant.StatusSub, "-v")
+	if len(prefix) > 0 {
+		statusCommand = append(statusCommand, "-p", prefix)
+	}
+	op, err = kubectl.PodExecString(flags, &pod, statusCommand)
+	if err != nil {
+		return err
+	}
+	return kubectl.SaveFile(directory, constant.BundleStatusFile, op, unMask)
 }
 
 // support receives details of user dir and kube object filters and kubeExtractInfo and kubeGetLogs functions
-func troubleShoot(flags *genericclioptions.ConfigFlags, podName string, appns string, ns string, cicContainer string, directory string, unMask bool) error {
+func troubleShoot(out io.Writer, flags *genericclioptions.ConfigFlags, pod apiv1.Pod, appns string, limits kubectl.LogLimits, directory string, unMask bool) error {
 	for _, appn := range strings.Fields(appns) {
 		err := kubeExtractInfo(flags, appn, directory, unMask)
 		if err != nil {
 			return err
 		}
 	}
-	err := kubeGetLogs(flags, podName, ns, cicContainer, directory, unMask)
+	err := kubeGetLogs(out, flags, pod, limits, directory, unMask)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// ingressOwnership saves which ingress controllers claim the Ingresses of the application namespaces, so that
+// Ingresses of another class or claimed by no NetScaler ingress controller are visible in the bundle
+func ingressOwnership(kClient request.K8sClient, flags *genericclioptions.ConfigFlags, pod apiv1.Pod, appns string, directory string, unMask bool) error {
+	ingressClasses, err := kClient.GetIngressClasses(flags)
+	if err != nil {
+		return err
+	}
+	classes := request.ControllerClasses(pod)
+	var buf bytes.Buffer
+	handled := "Ingresses without class and of the NetScaler IngressClasses"
+	if len(classes) > 0 {
+		handled = "the ingress classes " + strings.Join(classes, ", ")
+	}
+	controllers, err := kClient.DiscoverControllers(flags, true)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&buf, "The ingress controller pod %v/%v handles %v\n\n", pod.Namespace, pod.Name, handled)
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tINGRESS\tCLASS\tSELECTED CONTROLLER\tCLAIMED BY")
+	for _, appn := range strings.Fields(appns) {
+		ingresses, err := kClient.GetIngressDefinitions(flags, appn)
+		if err != nil {
+			return err
+		}
+		ownership, err := kClient.GetIngressOwnership(flags, appn, controllers)
+		if err != nil {
+			return err
+		}
+		for _, owner := range ownership {
+			selected := "no"
+			for _, ingress := range ingresses {
+				if ingress.Name == owner.Name && request.OwnsIngress(classes, ingressClasses, ingress) {
+					selected = "yes"
+				}
+			}
+			claimedBy := strings.Join(owner.ClaimedBy, ",")
+			if owner.Unclaimed() {
+				claimedBy = "UNCLAIMED"
+			} else if claimedBy == "" {
+				claimedBy = owner.Controller
+			}
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", owner.Namespace, owner.Name, orNone(owner.Class), selected, claimedBy)
+		}
+	}
+	w.Flush()
+	return kubectl.SaveFile(directory, constant.IngressOwnerFile, buf.String(), unMask)
+}
+
+// orNone prints -- for empty cells
+func orNone(value string) string {
+	if value == "" {
+		return "--"
+	}
+	return value
+}
+
 // CreateCommand creates the cobra commands for support subcommand
 func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
 	supportCmdFlag := SupportCmdFlag{}
@@ -121,7 +233,10 @@ func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
 		Use:   "support",
 		Short: "Get NetScaler (show techsupport) and Ingress Controller support bundle",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			util.PrintError(support(flags, supportCmdFlag))
+			cluster.Exit(supportCmdFlag.clusters, cluster.Run(flags, supportCmdFlag.clusters, false,
+				func(out io.Writer, flags *genericclioptions.ConfigFlags, context string) error {
+					return support(out, flags, context, supportCmdFlag)
+				}))
 			return nil
 		},
 	}
@@ -129,20 +244,34 @@ func CreateCommand(flags *genericclioptions.ConfigFlags) *cobra.Command {
 	return cmd
 }
 
-// support receives user inputs and filters kubernetes object and runs plugin file with support sub in cic container
-func support(flags *genericclioptions.ConfigFlags, supportCmdFlag SupportCmdFlag) error {
+// support receives user inputs and filters kubernetes object and runs plugin file with support sub in cic container.
+// The name of the bundle directory includes the context when several contexts are collected at once.
+func support(out io.Writer, flags *genericclioptions.ConfigFlags, context string, supportCmdFlag SupportCmdFlag) error {
 	/*********************************************************
 	 * kClient cannot be initialized in main to be in sync   *
 	 * with Cobra behaviour for defered flags init post RunE *
 	 *********************************************************/
+	if bundle.Enabled() {
+		return errors.New("support bundle cannot be collected with --from-bundle")
+	}
+	// Unmasked IP addresses must not be left in clear text on the machine running the plugin
+	if *supportCmdFlag.unMask && *supportCmdFlag.encryptTo == "" {
+		return errors.New("--unhideIP requires --encrypt-to, a support bundle with unmasked IP addresses must be encrypted")
+	}
+	var recipients bundle.Recipients
+	if *supportCmdFlag.encryptTo != "" {
+		var err error
+		recipients, err = bundle.ReadRecipients(*supportCmdFlag.encryptTo)
+		if err != nil {
+			return err
+		}
+	}
 	kClient, err := request.NewK8sClient(flags)
 	if err != nil {
-		fmt.Println("unable to init K8s Client: " + err.Error())
-		os.Exit(1)
-
+		return errors.New("unable to init K8s Client: " + err.Error())
 	}
 	var flagCommand []string
-	pod, cicContainer, cpxContainer, err := kClient.ChoosePod(flags, *supportCmdFlag.pod, *supportCmdFlag.deployment, *supportCmdFlag.selector)
+	pod, cicContainer, cpxContainer, err := kClient.ChoosePod(flags, *supportCmdFlag.podSelector)
 	if err != nil {
 		return err
 	}
@@ -154,49 +283,101 @@ func support(flags *genericclioptions.ConfigFlags, supportCmdFlag SupportCmdFlag
 		}
 	}
 	validVer, mismatch, err := kubectl.ValidVersion(flags, pod, cicContainer)
-	util.CmdErrorHandling(err)
+	if err != nil {
+		return util.CmdError(err)
+	}
 	if !validVer {
-		fmt.Print(mismatch)
+		fmt.Fprint(out, mismatch)
 		return nil
 	}
 	t := time.Now().UTC()
-	dir = dir + "/" + constant.DirPrefix + t.Format(constant.DateFormat)
+	if context != "" {
+		dir = dir + "/" + constant.DirPrefix + dirName(context) + "_" + t.Format(constant.DateFormat)
+	} else {
+		dir = dir + "/" + constant.DirPrefix + t.Format(constant.DateFormat)
+	}
+	if *supportCmdFlag.encryptTo != "" {
+		// The files collected so far are encrypted even when the collection fails
+		defer func() {
+			if _, err := os.Stat(dir); err != nil {
+				return
+			}
+			archive, err := bundle.Encrypt(dir, recipients)
+			if err != nil {
+				fmt.Fprintln(out, "Error while encrypting the support bundle, the files are present in "+dir+": "+err.Error())
+				return
+			}
+			fmt.Fprintln(out, "The encrypted support bundle is present in "+archive)
+		}()
+	}
 
 	if !(*supportCmdFlag.skipNsBundleFlag) {
 		if len(cicContainer) > 0 {
 			flagCommand = []string{"-c", cicContainer, "--", constant.PyCmd, constant.PluginFile, "-c", constant.SupportSub}
 		} else {
-			flagCommand = []string{"-c", cpxContainer, "--", constant.PyCmd, constant.PluginFile, "-c", constant.SupportSub}
+			flagCommand = []string{"--", constant.PyCmd, constant.PluginFile, "-c", constant.SupportSub}
 		}
-		fmt.Print("Extracting show tech support information, this may take minutes")
+		fmt.Fprint(out, "Extracting show tech support information, this may take minutes")
 		op, err := kubectl.PodExecString(flags, &pod, flagCommand)
 		if op != "" {
-			fmt.Print("\n" + op)
+			fmt.Fprint(out, "\n"+op)
 		}
-		util.CmdErrorHandling(err)
-		if len(cicContainer) > 0 {
-			flagCommand = []string{"--", constant.ReadLinkCmd, "-f", constant.StsSymLink}
+		if err != nil {
+			return util.CmdError(err)
+		}
+		// The show tech support archive is written in the NetScaler CPX container, a VPX/MPX keeps it
+		if len(cpxContainer) > 0 {
+			flagCommand = []string{"-c", cpxContainer, "--", constant.ReadLinkCmd, "-f", constant.StsSymLink}
 			dirNameOP, err := kubectl.PodExecString(flags, &pod, flagCommand)
-			util.CmdErrorHandling(err)
+			if err != nil {
+				return util.CmdError(err)
+			}
 			dirNameSlice := strings.Split(dirNameOP, "\n/")
 			dirName := strings.TrimSpace(dirNameSlice[len(dirNameSlice)-1])
-			flagCommand = []string{pod.Name + ":" + dirName, dir + "/" + constant.StsOp}
+			flagCommand = []string{"-c", cpxContainer, pod.Name + ":" + dirName, dir + "/" + constant.StsOp}
 			_, err = kubectl.PodCPString(flags, &pod, flagCommand)
 			if err != nil {
 				return err
 			}
 		} else {
-			fmt.Print("\n" + constant.NonCICSTSComment + constant.StsSymLink)
+			fmt.Fprint(out, "\n"+constant.NonCICSTSComment+constant.StsSymLink)
 		}
 	} else {
-		fmt.Println(constant.NoSTSComment)
+		fmt.Fprintln(out, constant.NoSTSComment)
+	}
+	fmt.Fprintln(out, "\nExtracting NetScaler configuration and status")
+	err = netScalerInfo(flags, pod, cicContainer, *supportCmdFlag.prefix, dir+"/"+constant.BundleNSDir, *supportCmdFlag.unMask)
+	if err != nil {
+		fmt.Fprintln(out, "Error while getting NetScaler configuration and status: "+err.Error())
 	}
-	fmt.Println("\nExtracting Kubernetes information")
+	fmt.Fprintln(out, "\nExtracting Kubernetes information")
 
-	err = troubleShoot(flags, pod.Name, *supportCmdFlag.appns, pod.Namespace, cicContainer, dir+"/"+"kube_info", *supportCmdFlag.unMask)
+	limits := kubectl.LogLimits{Since: *supportCmdFlag.since, Tail: *supportCmdFlag.tail, MaxBytes: *supportCmdFlag.maxLogBytes}
+	err = troubleShoot(out, flags, pod, *supportCmdFlag.appns, limits, dir+"/"+constant.KubeInfoDir, *supportCmdFlag.unMask)
 	if err != nil {
 		return err
 	}
-	fmt.Println("The support files are present in " + dir)
+	err = ingressOwnership(kClient, flags, pod, *supportCmdFlag.appns, dir+"/"+constant.KubeInfoDir, *supportCmdFlag.unMask)
+	if err != nil {
+		fmt.Fprintln(out, "Error while getting the ingress classes: "+err.Error())
+	}
+	fmt.Fprintln(out, "\nExtracting cluster networking information")
+	err = networkInfo(out, kClient, flags, pod, dir+"/"+constant.KubeInfoDir+"/"+constant.NetworkDir, *supportCmdFlag.unMask)
+	if err != nil {
+		fmt.Fprintln(out, "Error while getting cluster networking information: "+err.Error())
+	}
+	if *supportCmdFlag.encryptTo == "" {
+		fmt.Fprintln(out, "The support files are present in "+dir)
+	}
 	return nil
 }
+
+// dirName replaces the characters of a context name which are not safe in a directory name
+func dirName(context string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '.' {
+			return r
+		}
+		return '-'
+	}, context)
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package completion completes the flags selecting the ingress controller and its ingresses from the cluster
+package completion
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/request"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/settings"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// lister returns the candidate values of a flag
+type lister func(kClient request.K8sClient, flags *genericclioptions.ConfigFlags) ([]string, error)
+
+// listers of the completed flags by flag name
+var listers = map[string]lister{
+	"pod":         pods,
+	"deployment":  ownerNames("Deployment"),
+	"daemonset":   ownerNames("DaemonSet"),
+	"statefulset": ownerNames("StatefulSet"),
+	"owner":       ownerNames(""),
+	"label":       labels,
+	"ingress":     ingresses,
+	"prefix":      prefixes,
+}
+
+// Register adds the completion of the pod, workload, label, ingress and prefix flags to root and all its subcommands
+func Register(root *cobra.Command, flags *genericclioptions.ConfigFlags) {
+	for name, list := range listers {
+		if root.Flags().Lookup(name) != nil {
+			root.RegisterFlagCompletionFunc(name, complete(flags, list))
+		}
+	}
+	for _, cmd := range root.Commands() {
+		Register(cmd, flags)
+	}
+}
+
+// complete returns the completion function of a flag, the values are read from the cluster or the support bundle
+func complete(flags *genericclioptions.ConfigFlags, list lister) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// PersistentPreRunE does not run while completing, the target of the configuration file gives the namespace
+		if err := settings.Apply(cmd); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		// A shell waiting for an unreachable cluster would hang
+		if flags.Timeout == nil || *flags.Timeout == "" || *flags.Timeout == "0" {
+			timeout := constant.CompletionTimeout
+			flags.Timeout = &timeout
+		}
+		kClient, err := request.NewK8sClient(flags)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		values, err := list(kClient, flags)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		candidates := make([]string, 0, len(values))
+		for _, value := range values {
+			if value != "" && strings.HasPrefix(value, toComplete) && !util.ContainsString(candidates, value) {
+				candidates = append(candidates, value)
+			}
+		}
+		sort.Strings(candidates)
+		return candidates, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// pods returns the names of the ingress controller pods of the namespace
+func pods(kClient request.K8sClient, flags *genericclioptions.ConfigFlags) ([]string, error) {
+	controllers, err := kClient.DiscoverControllers(flags, false)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(controllers))
+	for _, ctrl := range controllers {
+		names = append(names, ctrl.Name)
+	}
+	return names, nil
+}
+
+// ownerNames returns the lister of the names of the workloads of kind owning the ingress controller pods of the
+// namespace, or of the kind/name of all their owners when kind is empty
+func ownerNames(kind string) lister {
+	return func(kClient request.K8sClient, flags *genericclioptions.ConfigFlags) ([]string, error) {
+		controllers, err := kClient.DiscoverControllers(flags, false)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0)
+		for _, ctrl := range controllers {
+			for _, ref := range kClient.PodOwners(ctrl.Pod) {
+				if kind == "" {
+					names = append(names, strings.ToLower(ref.Kind)+"/"+ref.Name)
+				} else if ref.Kind == kind {
+					names = append(names, ref.Name)
+				}
+			}
+		}
+		return names, nil
+	}
+}
+
+// labels returns the key=value labels of the ingress controller pods of the namespace
+func labels(kClient request.K8sClient, flags *genericclioptions.ConfigFlags) ([]string, error) {
+	controllers, err := kClient.DiscoverControllers(flags, false)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0)
+	for _, ctrl := range controllers {
+		for key, value := range ctrl.Pod.Labels {
+			// The hash changes with every rollout
+			if key != "pod-template-hash" {
+				values = append(values, key+"="+value)
+			}
+		}
+	}
+	return values, nil
+}
+
+// ingresses returns the names of the ingresses of all namespaces
+func ingresses(kClient request.K8sClient, flags *genericclioptions.ConfigFlags) ([]string, error) {
+	list, err := kClient.GetIngressDefinitions(flags, "")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list))
+	for _, ingress := range list {
+		names = append(names, ingress.Name)
+	}
+	return names, nil
+}
+
+// prefixes returns the NS_APPS_NAME_PREFIX of the ingress controllers of the namespace, k8s when it is not set
+func prefixes(kClient request.K8sClient, flags *genericclioptions.ConfigFlags) ([]string, error) {
+	controllers, err := kClient.DiscoverControllers(flags, false)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0)
+	for _, ctrl := range controllers {
+		if ctrl.Kind != constant.KindCIC {
+			continue
+		}
+		values = append(values, request.ControllerPrefix(ctrl.Pod))
+	}
+	return values, nil
+}
@@ -19,10 +19,59 @@ const (
 	CicDeployFile      = "cic_deployment.txt"
 	CicLogsFile        = "cic_logs.txt"
 	CicLogsRestart     = "restarted_pod_logs.txt"
+	ContainerLogsDir   = "containers"
+	LogsFileSuffix     = ".txt"
+	PreviousLogsSuffix = "_previous.txt"
+	KubeInfoDir        = "kube_info"
+	BundleNSDir        = "netscaler"
+	BundleConfFile     = "running_config.txt"
+	BundleStatusFile   = "status.txt"
+	NitroUserEnv       = "NS_USER"
+	NitroPasswordEnv   = "NS_PASSWORD"
+	NitroDefaultUser   = "nsroot"
 	RegexpMaskIP       = `((25[0-5]|(2[0-4]|1\d|[1-9]|)\d)\.?\b){4}`
 	NoSTSComment       = "Skipping show tech support collection. For show tech support on NetScaler rerun with by removing --skip-nsbundle option"
 	NonCICSTSComment   = "This CIC is connected to VPX/MPX NetScaler appliance. Please securely download support artifact from NetScaler location: "
 	UnMaskedIP         = `X.X.X.X`
+	KindCIC            = "CIC"
+	KindCPX            = "CPX"
+	KindGSLB           = "GSLB"
+	KindIPAM           = "IPAM"
+	KindGateway        = "Gateway"
+	ModeSidecar        = "sidecar"
+	ModeStandalone     = "standalone"
+	ModeCPX            = "CPX"
+	LocalCPXTarget     = "local CPX"
+	PickFirst          = "first"
+	PickNewest         = "newest"
+	PickReady          = "ready"
+	CitrixGroup        = "citrix.com"
+	GTPResource        = "globaltrafficpolicies"
+	GSEResource        = "globalserviceentries"
+	VIPResource        = "vips"
+	HealthPass         = "PASS"
+	HealthWarn         = "WARN"
+	HealthFail         = "FAIL"
+	IngressClassesArg  = "--ingress-classes"
+	IngressClassesEnv  = "INGRESS_CLASSES"
+	ClassAnnotation    = "kubernetes.io/ingress.class"
+	IngressOwnerFile   = "ingress_classes.txt"
+	NetworkDir         = "network"
+	NetworkFile        = "network_summary.txt"
+	KubeProxy          = "kube-proxy"
+	NodeWatchArg       = "--feature-node-watch"
+	RoleCIC            = "cic"
+	RoleCPX            = "cpx"
+	RoleExporter       = "exporter"
+	RoleAll            = "all"
+	CurrentLogs        = "current"
+	PreviousLogs       = "previous"
+	ConfigFileName     = "kubectl-netscaler.yaml"
+	ConfigFileEnv      = "KUBECTL_NETSCALER_CONFIG"
+	CompletionTimeout  = "5s"
+	PrefixEnv          = "NS_APPS_NAME_PREFIX"
+	DefaultPrefix      = "k8s"
+	PassphraseEnv      = "NS_BUNDLE_PASSPHRASE"
 
 	/*************************WARNING*************************
 	 * Please make sure to maintain the same name for json   *
@@ -39,5 +88,50 @@ const (
 	SkipNSBundleFlag = `{"CmdLName": "skip-nsbundle", "CmdSName": "","DefValueB": false, "CmdDesc": "This option enables to extract techsupport from NetScaler. By default this is set to false"}`
 	DirFlag          = `{"CmdLName": "dir", "CmdSName": "d","DefValueStr": "", "CmdDesc": "Specify the absolute path of the directory to store support files. If not provided current directory will be used."}`
 	AppNSFlag        = `{"CmdLName": "appns", "CmdSName": "","DefValueStr": "default", "CmdDesc": "List of space separated namespaces (within quotes) from where Kubernetes resource details such as ingress, services, pods and crds are extracted (eg: \" default namespace1 namespace2\")"}`
-	UnmaskFlag       = `{"CmdLName": "unhideIP", "CmdSName": "","DefValueB": false, "CmdDesc": "Set this to unhide IPs while collecting Kubernetes information. By default this is set to false."}`
+	UnmaskFlag       = `{"CmdLName": "unhideIP", "CmdSName": "","DefValueB": false, "CmdDesc": "Set this to unhide IPs while collecting Kubernetes information, only allowed with --encrypt-to. By default this is set to false."}`
+	PickFlag         = `{"CmdLName": "pick", "CmdSName": "","DefValueStr": "", "CmdDesc": "Choose the pod when the selector matches several ingress controller pods. Supported values are first, newest and ready. If not set, a prompt is shown on a terminal"}`
+	ContainersFlag   = `{"CmdLName": "containers", "CmdSName": "","DefValueStr": "cic", "CmdDesc": "Comma separated list of containers to read logs from. Supported values are cic, cpx, exporter, all or container names"}`
+	SinceFlag        = `{"CmdLName": "since", "CmdSName": "","DefValueStr": "", "CmdDesc": "Only return logs newer than a relative duration like 5s, 2m, or 3h. Defaults to all logs"}`
+	TailFlag         = `{"CmdLName": "tail", "CmdSName": "","DefValueInt": -1, "CmdDesc": "Lines of recent log to display. Defaults to -1 showing all log lines"}`
+	FollowFlag       = `{"CmdLName": "follow", "CmdSName": "f","DefValueB": false, "CmdDesc": "Specify if the logs should be streamed"}`
+	PreviousFlag     = `{"CmdLName": "previous", "CmdSName": "","DefValueB": false, "CmdDesc": "If this option is set, logs of the previous instance of the containers are displayed"}`
+	LevelFlag        = `{"CmdLName": "level", "CmdSName": "","DefValueStr": "", "CmdDesc": "Minimum level of ingress controller log records to display: DEBUG, INFO, WARNING, ERROR or CRITICAL"}`
+	LogIngressFlag   = `{"CmdLName": "ingress", "CmdSName": "i","DefValueStr": "", "CmdDesc": "Display only log records mentioning the given Kubernetes Ingress Resource"}`
+	LogNSFlag        = `{"CmdLName": "app-namespace", "CmdSName": "","DefValueStr": "", "CmdDesc": "Display only log records mentioning the given application namespace"}`
+	EntityFlag       = `{"CmdLName": "entity", "CmdSName": "","DefValueStr": "", "CmdDesc": "Display only log records mentioning the given NetScaler entity name"}`
+	SignaturesFlag   = `{"CmdLName": "signatures", "CmdSName": "","DefValueStr": "", "CmdDesc": "YAML or JSON file with additional log signatures. A signature with the id of a built-in signature replaces it"}`
+	EventsFlag       = `{"CmdLName": "events", "CmdSName": "","DefValueB": false, "CmdDesc": "If this option is set, Kubernetes Warning events of the ingresses, their backend services and pods and of the ingress controller pod are displayed."}`
+	FromBundleFlag   = `{"CmdLName": "from-bundle", "CmdSName": "","DefValueStr": "", "CmdDesc": "Path of a support bundle (nssupport_* directory) to analyze offline instead of the cluster"}`
+	StsPrefixFlag    = `{"CmdLName": "prefix", "CmdSName": "p","DefValueStr": "", "CmdDesc": "Specify the name of the Prefix provided while deploying the Ingress Controller, used to record the NetScaler entity status in the bundle"}`
+	NitroURLFlag     = `{"CmdLName": "nitro-url", "CmdSName": "","DefValueStr": "", "CmdDesc": "URL of a NetScaler NITRO endpoint, such as the simulator, to query directly. Credentials are read from the NS_USER and NS_PASSWORD environment variables, required unless the endpoint is a local simulator"}`
+	ListenFlag       = `{"CmdLName": "listen", "CmdSName": "","DefValueStr": "127.0.0.1:9080", "CmdDesc": "Address on which the server listens"}`
+	FixtureFlag      = `{"CmdLName": "fixture", "CmdSName": "","DefValueStr": "", "CmdDesc": "JSON file with NITRO objects per resource type to seed the simulator with"}`
+	ConfDumpFlag     = `{"CmdLName": "conf", "CmdSName": "","DefValueStr": "", "CmdDesc": "Running configuration saved from the conf subcommand to seed the simulator with"}`
+	MetricsAddrFlag  = `{"CmdLName": "listen", "CmdSName": "","DefValueStr": ":9877", "CmdDesc": "Address on which the metrics are served"}`
+	IntervalFlag     = `{"CmdLName": "interval", "CmdSName": "","DefValueStr": "30s", "CmdDesc": "Interval between two polls of the NetScaler entity status"}`
+	MinMembersFlag   = `{"CmdLName": "min-members-up", "CmdSName": "","DefValueInt": 50, "CmdDesc": "Minimum percentage of service group members that must be up"}`
+	MaxRestartsFlag  = `{"CmdLName": "max-restarts", "CmdSName": "","DefValueInt": 5, "CmdDesc": "Maximum number of restarts of the ingress controller pod containers"}`
+	IngressNSFlag    = `{"CmdLName": "app-namespace", "CmdSName": "","DefValueStr": "", "CmdDesc": "Namespace of the application resources to check, all namespaces if not set"}`
+	ExpiryDaysFlag   = `{"CmdLName": "expiry-days", "CmdSName": "","DefValueInt": 30, "CmdDesc": "Certificates expiring within this number of days are reported as expiring soon"}`
+	AllNamespaceFlag = `{"CmdLName": "all-namespaces", "CmdSName": "A","DefValueB": false, "CmdDesc": "If this option is set, NetScaler controllers are listed across all namespaces."}`
+	ServiceFlag      = `{"CmdLName": "service", "CmdSName": "","DefValueStr": "", "CmdDesc": "Specify the option to retrieve config status of a particular Kubernetes Service of type LoadBalancer"}`
+	AllServicesFlag  = `{"CmdLName": "all-services", "CmdSName": "","DefValueB": false, "CmdDesc": "If this option is set, config status of all Kubernetes Services of type LoadBalancer of the namespace is displayed."}`
+	ServiceNSFlag    = `{"CmdLName": "app-namespace", "CmdSName": "","DefValueStr": "", "CmdDesc": "Namespace of the Services, the namespace of the command if not set"}`
+	FilenameFlag     = `{"CmdLName": "filename", "CmdSName": "f","DefValueStr": "", "CmdDesc": "Comma separated manifest files or directories of Ingress resources to check, - for the standard input. The Ingresses of the cluster are checked if not set"}`
+	ContextsFlag     = `{"CmdLName": "contexts", "CmdSName": "","DefValueStr": "", "CmdDesc": "Comma separated kubeconfig contexts to run the command against concurrently, results are grouped by context"}`
+	AllContextsFlag  = `{"CmdLName": "all-contexts", "CmdSName": "","DefValueB": false, "CmdDesc": "If this option is set, the command runs concurrently against all the contexts of the kubeconfig."}`
+	TargetFlag       = `{"CmdLName": "target", "CmdSName": "","DefValueStr": "", "CmdDesc": "Name of the target of the plugin configuration file providing the context, namespace, selector and prefix, the current target of the file if not set"}`
+	ContainerFlag    = `{"CmdLName": "container", "CmdSName": "","DefValueStr": "", "CmdDesc": "Name of the ingress controller container, detected from the image, entrypoint and environment of the containers if not set"}`
+	CPXContainerFlag = `{"CmdLName": "cpx-container", "CmdSName": "","DefValueStr": "", "CmdDesc": "Name of the NetScaler CPX container, detected from the image and environment of the containers if not set"}`
+	VerboseRootFlag  = `{"CmdLName": "verbose", "CmdSName": "","DefValueB": false, "CmdDesc": "If this option is set, the choice of the controller pod and containers is explained."}`
+	EncryptToFlag    = `{"CmdLName": "encrypt-to", "CmdSName": "","DefValueStr": "", "CmdDesc": "age recipients or OpenPGP public key file to encrypt the support bundle to, the unencrypted files are removed"}`
+	IdentityFlag     = `{"CmdLName": "identity", "CmdSName": "","DefValueStr": "", "CmdDesc": "age identity or OpenPGP private key file to decrypt the support bundle with"}`
+	LogsSinceFlag    = `{"CmdLName": "since", "CmdSName": "","DefValueStr": "", "CmdDesc": "Only collect logs newer than a relative duration like 5s, 2m, or 3h. Defaults to all logs"}`
+	LogsTailFlag     = `{"CmdLName": "tail", "CmdSName": "","DefValueInt": -1, "CmdDesc": "Lines of recent log to collect per container. Defaults to -1 collecting all log lines"}`
+	MaxLogBytesFlag  = `{"CmdLName": "max-log-bytes", "CmdSName": "","DefValueInt": 10485760, "CmdDesc": "Maximum size of each container log file, the most recent logs are kept. 0 for no limit"}`
+	ExtractDirFlag   = `{"CmdLName": "dir", "CmdSName": "d","DefValueStr": "", "CmdDesc": "Directory to extract the support bundle to, the current directory if not set"}`
+	AnyReplicaFlag   = `{"CmdLName": "any-replica", "CmdSName": "","DefValueB": false, "CmdDesc": "If this option is set, any replica is chosen instead of the leader holding the leader-election lock of the ingress controller."}`
+	DaemonSetFlag    = `{"CmdLName": "daemonset", "CmdSName": "","DefValueStr": "", "CmdDesc": "Name of the ingress controller DaemonSet"}`
+	StatefulSetFlag  = `{"CmdLName": "statefulset", "CmdSName": "","DefValueStr": "", "CmdDesc": "Name of the ingress controller StatefulSet"}`
+	OwnerFlag        = `{"CmdLName": "owner", "CmdSName": "","DefValueStr": "", "CmdDesc": "Kind and name of the workload owning the ingress controller pods, such as rollout/cic, followed through the owner references of the pods"}`
 )
@@ -14,17 +14,23 @@ limitations under the License.
 package kubectl
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"syscall"
 
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/bundle"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nitro"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
 
 	apiv1 "k8s.io/api/core/v1"
@@ -33,6 +39,10 @@ import (
 
 func ValidVersion(flags *genericclioptions.ConfigFlags, pod apiv1.Pod, cicContainer string) (bool, string, error) {
 	versionSupportFrom := constant.VersionSupportFrom
+	// The version was validated when the support bundle was collected
+	if bundle.Enabled() {
+		return true, "", nil
+	}
 	var flagCommand []string
 	if cicContainer == "" {
 		flagCommand = []string{"--", "cat", constant.VersionFile}
@@ -84,6 +94,14 @@ func createDirFile(directory string, filename string, content string) error {
 	return nil
 }
 
+// SaveFile masks IPs unless unMask is set and stores content in directory
+func SaveFile(directory string, filename string, content string, unMask bool) error {
+	if !unMask {
+		content = maskIP(content)
+	}
+	return createDirFile(directory, filename, content)
+}
+
 // RunCmdSaveFile takes a pod and a command, uses kubectl get and set to extract kube object details
 func RunCmdSaveFile(flags *genericclioptions.ConfigFlags, ns string, args []string, flag bool, dir string, unMask bool) error {
 	var maskedOut string
@@ -91,37 +109,20 @@ func RunCmdSaveFile(flags *genericclioptions.ConfigFlags, ns string, args []stri
 	kArgs = append(kArgs, "-n", ns)
 	kArgs = append(kArgs, args...)
 	out, err := exec.Command("kubectl", kArgs...).Output()
-	if err != nil && args[0] != "logs" {
+	if err != nil {
 		fmt.Println(err)
 		return err
 	}
-	absPathCicLog := dir + "/" + ns + "/" + constant.CicLogsDir
-	absPathCicDep := dir + "/" + ns + "/" + constant.CicDeployDir
 	if unMask {
 		maskedOut = string(out)
 	} else {
 		maskedOut = maskIP(string(out))
 	}
 	if flag {
-		if args[0] == "logs" {
-			if args[1] == "-p" {
-				err = createDirFile(absPathCicLog, constant.CicLogsRestart, maskedOut)
-				if err != nil {
-					return err
-				}
-			} else {
-				err = createDirFile(absPathCicLog, constant.CicLogsFile, maskedOut)
-				if err != nil {
-					return err
-				}
-			}
-		} else {
-			err = createDirFile(absPathCicDep, constant.CicDeployFile, maskedOut)
-			if err != nil {
-				return err
-			}
+		err = createDirFile(dir+"/"+ns+"/"+constant.CicDeployDir, constant.CicDeployFile, maskedOut)
+		if err != nil {
+			return err
 		}
-
 	} else {
 		err = createDirFile(dir+"/"+ns+"/"+args[1], args[0]+"_"+args[1]+".txt", maskedOut)
 		if err != nil {
@@ -138,13 +139,214 @@ func PodExecString(flags *genericclioptions.ConfigFlags, pod *apiv1.Pod, args []
 	return ExecToString(flags, args)
 }
 
+// NetScalerStatus runs the verbose status subcommand of the plugin file in the cic container for the prefix.
+// With --from-bundle the status recorded in the bundle is returned.
+func NetScalerStatus(flags *genericclioptions.ConfigFlags, pod *apiv1.Pod, cicContainer string, prefix string) (string, error) {
+	if bundle.Enabled() {
+		return bundle.ReadNetScalerFile(constant.BundleStatusFile)
+	}
+	var args []string
+	if cicContainer != "" {
+		args = []string{"-c", cicContainer}
+	}
+	args = append(args, "--", constant.PyCmd, constant.PluginFile, "-c", constant.StatusSub, "-v")
+	if len(prefix) > 0 {
+		args = append(args, "-p", prefix)
+	}
+	return PodExecString(flags, pod, args)
+}
+
+// NetScalerConf returns the running configuration of NetScaler, read from the --nitro-url endpoint when given,
+// from the support bundle with --from-bundle, otherwise with the conf subcommand of the plugin file in the cic container
+func NetScalerConf(flags *genericclioptions.ConfigFlags, pod *apiv1.Pod, cicContainer string) (string, error) {
+	if nitro.URL != "" {
+		client := nitro.NewClient(nitro.URL, "", "")
+		if err := client.Login(); err != nil {
+			return "", err
+		}
+		defer client.Logout()
+		return client.RunningConfig()
+	}
+	if bundle.Enabled() {
+		return bundle.ReadNetScalerFile(constant.BundleConfFile)
+	}
+	var args []string
+	if cicContainer != "" {
+		args = []string{"-c", cicContainer}
+	}
+	args = append(args, "--", constant.PyCmd, constant.PluginFile, "-c", constant.ConfSub)
+	return PodExecString(flags, pod, args)
+}
+
 func PodCPString(flags *genericclioptions.ConfigFlags, pod *apiv1.Pod, args []string) (string, error) {
 	args = append([]string{"cp", "-n", pod.Namespace}, args...)
 	return ExecToString(flags, args)
 }
 
+// PodLogs runs kubectl logs for a pod and hands its stdout to fn while the command runs.
+// With --from-bundle the ingress controller logs saved in the bundle are read instead.
+func PodLogs(flags *genericclioptions.ConfigFlags, pod *apiv1.Pod, args []string, fn func(io.Reader) error) error {
+	if bundle.Enabled() {
+		logs, err := bundle.OpenLogs(pod.Namespace, logsContainer(pod, args), util.ContainsString(args, "-p"))
+		if err != nil {
+			return err
+		}
+		defer logs.Close()
+		if tail := logsTail(args); tail >= 0 {
+			lines, err := tailLines(logs, tail)
+			if err != nil {
+				return err
+			}
+			return fn(lines)
+		}
+		return fn(logs)
+	}
+	kArgs := getKubectlConfigFlags(flags)
+	kArgs = append(kArgs, "logs", "-n", pod.Namespace, pod.Name)
+	kArgs = append(kArgs, args...)
+	cmd := exec.Command("kubectl", kArgs...)
+	stdErr := bytes.NewBuffer(make([]byte, 0))
+	cmd.Stderr = stdErr
+	op, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+	err = fn(op)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+	if err = cmd.Wait(); err != nil && stdErr.Len() > 0 {
+		return errors.New(strings.TrimSpace(stdErr.String()))
+	}
+	return err
+}
+
+// NoPreviousLogs reports whether the previous logs of a container could not be read because it did not restart,
+// or because they are not saved in the support bundle
+func NoPreviousLogs(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || (err != nil && strings.Contains(err.Error(), "previous terminated container"))
+}
+
+// logsTail returns the number of lines given with --tail in kubectl logs arguments, -1 for all the lines
+func logsTail(args []string) int {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--tail=") {
+			if tail, err := strconv.Atoi(strings.TrimPrefix(arg, "--tail=")); err == nil {
+				return tail
+			}
+		}
+	}
+	return -1
+}
+
+// tailLines returns the last n lines of r, as kubectl logs --tail does for a running container
+func tailLines(r io.Reader, n int) (io.Reader, error) {
+	lines := make([]string, 0)
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			lines = append(lines, line)
+			if len(lines) > n {
+				lines = lines[1:]
+			}
+		}
+		if err == io.EOF {
+			return strings.NewReader(strings.Join(lines, "")), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// logsContainer returns the container given with -c in kubectl logs arguments, kubectl defaults to the first container
+func logsContainer(pod *apiv1.Pod, args []string) string {
+	for i, arg := range args {
+		if arg == "-c" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
+
+// LogLimits bound the container logs saved in a support bundle
+type LogLimits struct {
+	Since    string
+	Tail     int
+	MaxBytes int
+}
+
+// SaveContainerLogs saves the current or previous logs of a container of pod in the containers directory of the
+// controller logs. Only the most recent MaxBytes are kept, it returns whether older logs were dropped.
+func SaveContainerLogs(flags *genericclioptions.ConfigFlags, pod *apiv1.Pod, container string, previous bool, limits LogLimits, dir string, unMask bool) (bool, error) {
+	args := []string{"-c", container, "--tail=" + strconv.Itoa(limits.Tail)}
+	if len(limits.Since) > 0 {
+		args = append(args, "--since="+limits.Since)
+	}
+	name := container + constant.LogsFileSuffix
+	if previous {
+		args = append(args, "-p")
+		name = container + constant.PreviousLogsSuffix
+	}
+	logs := &tailBuffer{max: limits.MaxBytes}
+	err := PodLogs(flags, pod, args, func(op io.Reader) error {
+		_, err := io.Copy(logs, op)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return logs.truncated(), SaveFile(dir+"/"+pod.Namespace+"/"+constant.CicLogsDir+"/"+constant.ContainerLogsDir, name, logs.String(), unMask)
+}
+
+// tailBuffer keeps the last max bytes written to it, all of them when max is not positive
+type tailBuffer struct {
+	buf     []byte
+	max     int
+	dropped bool
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	// Dropping once the buffer doubles keeps the copies linear in the size of the logs
+	if b.max > 0 && len(b.buf) > 2*b.max {
+		b.buf = append(b.buf[:0], b.buf[len(b.buf)-b.max:]...)
+		b.dropped = true
+	}
+	return len(p), nil
+}
+
+// String returns the kept logs starting at a line boundary
+func (b *tailBuffer) String() string {
+	if b.max <= 0 || len(b.buf) <= b.max {
+		return string(b.buf)
+	}
+	kept := b.buf[len(b.buf)-b.max:]
+	if i := bytes.IndexByte(kept, '\n'); i >= 0 && i+1 < len(kept) {
+		kept = kept[i+1:]
+	}
+	return string(kept)
+}
+
+// truncated tells whether older logs were dropped
+func (b *tailBuffer) truncated() bool {
+	return b.dropped || (b.max > 0 && len(b.buf) > b.max)
+}
+
 // ExecToString runs a kubectl subcommand and returns stdout as a string
 func ExecToString(flags *genericclioptions.ConfigFlags, args []string) (string, error) {
+	if bundle.Enabled() {
+		return "", fmt.Errorf("kubectl %v is not supported with --from-bundle", args[0])
+	}
 	kArgs := getKubectlConfigFlags(flags)
 	kArgs = append(kArgs, args...)
 	buf := bytes.NewBuffer(make([]byte, 0))
@@ -170,15 +372,12 @@ func execCommand(args []string) error {
 // Replaces the currently running process with the given command and puts to stdout
 func execToWriter(args []string, writer io.Writer) error {
 	cmd := exec.Command(args[0], args[1:]...)
-	op, err := cmd.StdoutPipe()
-	cmd.Stderr = cmd.Stdout
-	if err != nil {
-		return err
-	}
+	// Run returns once the output is copied, reading a pipe could lose the end of the output of short commands
+	cmd.Stdout = writer
+	cmd.Stderr = writer
 	shutdownCh := make(chan struct{})
 	go util.Indicator(shutdownCh)
-	go io.Copy(writer, op)
-	err = cmd.Run()
+	err := cmd.Run()
 	close(shutdownCh)
 	if err != nil {
 		return err
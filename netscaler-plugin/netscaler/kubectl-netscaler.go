@@ -17,9 +17,27 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/bundle"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/analyze"
+	supportbundle "github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/bundle"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/certs"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/conf"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/config"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/gslb"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/health"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/ipam"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/lint"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/list"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/logs"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/metrics"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/simulate"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/status"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/commands/support"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/completion"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nitro"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/settings"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
 
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -32,14 +50,39 @@ func main() {
 		Use:   "netscaler",
 		Short: "A Kubernetes plugin for inspecting Ingress Controller and associated NetScaler deployments",
 	}
-	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	// Respect some basic kubectl flags like --namespace
 	flags := genericclioptions.NewConfigFlags(true)
 	flags.AddFlags(rootCmd.PersistentFlags())
+	util.AddPersistentFlagStringVarP(rootCmd, &bundle.Path, []byte(constant.FromBundleFlag))
+	util.AddPersistentFlagStringVarP(rootCmd, &nitro.URL, []byte(constant.NitroURLFlag))
+	util.AddPersistentFlagStringVarP(rootCmd, &settings.TargetName, []byte(constant.TargetFlag))
+	util.AddPersistentFlagBoolVarP(rootCmd, &util.Verbose, []byte(constant.VerboseRootFlag))
+	// Flags not given on the command line are read from the target and defaults of the configuration file
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := settings.Apply(cmd); err != nil {
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			return err
+		}
+		return nil
+	}
 	// Add custom subcommands supported by plugin
 	rootCmd.AddCommand(status.CreateCommand(flags))
 	rootCmd.AddCommand(support.CreateCommand(flags))
 	rootCmd.AddCommand(conf.CreateCommand(flags))
+	rootCmd.AddCommand(list.CreateCommand(flags))
+	rootCmd.AddCommand(logs.CreateCommand(flags))
+	rootCmd.AddCommand(analyze.CreateCommand(flags))
+	rootCmd.AddCommand(health.CreateCommand(flags))
+	rootCmd.AddCommand(certs.CreateCommand(flags))
+	rootCmd.AddCommand(gslb.CreateCommand(flags))
+	rootCmd.AddCommand(ipam.CreateCommand(flags))
+	rootCmd.AddCommand(lint.CreateCommand(flags))
+	rootCmd.AddCommand(metrics.CreateCommand(flags))
+	rootCmd.AddCommand(simulate.CreateCommand(flags))
+	rootCmd.AddCommand(config.CreateCommand(flags))
+	rootCmd.AddCommand(supportbundle.CreateCommand())
+	completion.Register(rootCmd, flags)
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -0,0 +1,231 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nitro is a minimal client of the NetScaler NITRO REST API
+package nitro
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nsconf"
+)
+
+// URL of the NITRO endpoint given with --nitro-url, empty when NetScaler is reached through the ingress controller
+var URL string
+
+// Client keeps a NITRO session
+type Client struct {
+	BaseURL  string
+	Username string
+	Password string
+	token    string
+	http     *http.Client
+}
+
+// response is the envelope of every NITRO reply
+type response struct {
+	ErrorCode int    `json:"errorcode"`
+	Message   string `json:"message"`
+	Severity  string `json:"severity"`
+	SessionID string `json:"sessionid,omitempty"`
+}
+
+// Error is a NITRO error reply
+type Error struct {
+	StatusCode int
+	ErrorCode  int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("NITRO error %v (HTTP %v): %v", e.ErrorCode, e.StatusCode, e.Message)
+}
+
+// NewClient creates a client for baseURL, credentials default to the NS_USER and NS_PASSWORD environment variables.
+// The nsroot account of the simulator is only assumed for a loopback endpoint, so that no credential is guessed
+// against a remote NetScaler.
+func NewClient(baseURL string, username string, password string) *Client {
+	if username == "" {
+		username, password = os.Getenv(constant.NitroUserEnv), os.Getenv(constant.NitroPasswordEnv)
+	}
+	if username == "" && isLoopback(baseURL) {
+		username, password = constant.NitroDefaultUser, constant.NitroDefaultUser
+	}
+	return &Client{
+		BaseURL:  strings.TrimRight(baseURL, "/"),
+		Username: username,
+		Password: password,
+		http: &http.Client{
+			Timeout: 30 * time.Second,
+			// NetScaler management certificates are usually self signed
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		},
+	}
+}
+
+// isLoopback reports whether the host of the URL is a loopback address, where the simulator listens
+func isLoopback(baseURL string) bool {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+	host := parsed.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Login opens a NITRO session
+func (c *Client) Login() error {
+	if c.Username == "" {
+		return fmt.Errorf("no NITRO credentials for %v, set the %v and %v environment variables", c.BaseURL, constant.NitroUserEnv, constant.NitroPasswordEnv)
+	}
+	body := map[string]interface{}{"login": map[string]string{"username": c.Username, "password": c.Password}}
+	data, err := c.do(http.MethodPost, "/nitro/v1/config/login", body)
+	if err != nil {
+		return err
+	}
+	var reply response
+	if err = json.Unmarshal(data, &reply); err != nil {
+		return err
+	}
+	c.token = reply.SessionID
+	return nil
+}
+
+// Logout closes the NITRO session
+func (c *Client) Logout() error {
+	_, err := c.do(http.MethodPost, "/nitro/v1/config/logout", map[string]interface{}{"logout": map[string]string{}})
+	c.token = ""
+	return err
+}
+
+// Get returns the configuration of a resource type, or of the named resource
+func (c *Client) Get(resource string, name string) ([]nsconf.Object, error) {
+	return c.list("config", resource, name)
+}
+
+// Stat returns the statistics of a resource type, or of the named resource
+func (c *Client) Stat(resource string, name string) ([]nsconf.Object, error) {
+	return c.list("stat", resource, name)
+}
+
+// Delete removes the named resource
+func (c *Client) Delete(resource string, name string) error {
+	_, err := c.do(http.MethodDelete, "/nitro/v1/config/"+resource+"/"+url.PathEscape(name), nil)
+	return err
+}
+
+// RunningConfig returns the show run output
+func (c *Client) RunningConfig() (string, error) {
+	data, err := c.do(http.MethodGet, "/nitro/v1/config/nsrunningconfig", nil)
+	if err != nil {
+		return "", err
+	}
+	var reply struct {
+		NSRunningConfig struct {
+			Response string `json:"response"`
+		} `json:"nsrunningconfig"`
+	}
+	err = json.Unmarshal(data, &reply)
+	return reply.NSRunningConfig.Response, err
+}
+
+// list runs a GET on the config or stat API and returns the objects of the reply
+func (c *Client) list(api string, resource string, name string) ([]nsconf.Object, error) {
+	path := "/nitro/v1/" + api + "/" + resource
+	if name != "" {
+		path += "/" + url.PathEscape(name)
+	} else if strings.HasSuffix(resource, "_binding") {
+		// The bindings of all the entities are only returned on request
+		path += "?bulkbindings=yes"
+	}
+	data, err := c.do(http.MethodGet, path, nil)
+	if err != nil {
+		// errorcode 258 is returned for a resource which does not exist
+		if nitroErr, ok := err.(*Error); ok && nitroErr.ErrorCode == 258 {
+			return []nsconf.Object{}, nil
+		}
+		return nil, err
+	}
+	var reply map[string]json.RawMessage
+	if err = json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+	objects := make([]nsconf.Object, 0)
+	if raw, ok := reply[resource]; ok {
+		if err = json.Unmarshal(raw, &objects); err != nil {
+			// A single object is returned for some stat resources
+			var obj nsconf.Object
+			if json.Unmarshal(raw, &obj) != nil {
+				return nil, err
+			}
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+// do sends a request with the session cookie and turns NITRO error replies into errors
+func (c *Client) do(method string, path string, body interface{}) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, c.BaseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Cookie", "NITRO_AUTH_TOKEN="+c.token)
+	} else {
+		req.Header.Set("X-NITRO-USER", c.Username)
+		req.Header.Set("X-NITRO-PASS", c.Password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		var reply response
+		json.Unmarshal(data, &reply)
+		if reply.Message == "" {
+			reply.Message = strings.TrimSpace(string(data))
+		}
+		return nil, &Error{StatusCode: resp.StatusCode, ErrorCode: reply.ErrorCode, Message: reply.Message}
+	}
+	return data, nil
+}
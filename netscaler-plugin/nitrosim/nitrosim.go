@@ -0,0 +1,346 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nitrosim is a local NITRO API server serving NetScaler objects from a fixture or a saved
+// running configuration, so that the plugin can be exercised without a NetScaler.
+package nitrosim
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nsconf"
+)
+
+// NITRO error codes returned by the simulator
+const (
+	errNoSession   = 444
+	errBadLogin    = 354
+	errNoResource  = 258
+	errBadRequest  = 1092
+	errNotAllowed  = 1093
+	authCookieName = "NITRO_AUTH_TOKEN"
+)
+
+// Fixture is the JSON seed of the simulator, objects are listed per NITRO resource type
+type Fixture struct {
+	Config map[string][]nsconf.Object `json:"config"`
+	Stat   map[string][]nsconf.Object `json:"stat"`
+}
+
+// Simulator serves the NITRO login, logout, config and stat endpoints
+type Simulator struct {
+	Username string
+	Password string
+	mu       sync.Mutex
+	lines    []string
+	config   map[string][]nsconf.Object
+	stat     map[string][]nsconf.Object
+	sessions map[string]bool
+}
+
+// New creates an empty simulator accepting the given credentials
+func New(username string, password string) *Simulator {
+	return &Simulator{
+		Username: username,
+		Password: password,
+		config:   make(map[string][]nsconf.Object),
+		stat:     make(map[string][]nsconf.Object),
+		sessions: make(map[string]bool),
+	}
+}
+
+// LoadFixture seeds the simulator with a JSON fixture, the running configuration is rendered from the objects
+func (sim *Simulator) LoadFixture(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var fixture Fixture
+	if err = json.Unmarshal(content, &fixture); err != nil {
+		return fmt.Errorf("unable to parse fixture %v: %v", path, err)
+	}
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	for resource, objects := range fixture.Config {
+		sim.config[resource] = append(sim.config[resource], objects...)
+	}
+	for resource, objects := range fixture.Stat {
+		sim.stat[resource] = append(sim.stat[resource], objects...)
+	}
+	for _, resource := range nsconf.Resources() {
+		for _, obj := range fixture.Config[resource] {
+			sim.lines = append(sim.lines, nsconf.Command(resource, obj))
+		}
+	}
+	return nil
+}
+
+// LoadConf seeds the simulator with show run output, such as the output of the conf subcommand
+func (sim *Simulator) LoadConf(text string) {
+	conf := nsconf.Parse(text)
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.lines = append(sim.lines, conf.Lines...)
+	for resource, objects := range conf.Objects {
+		sim.config[resource] = append(sim.config[resource], objects...)
+	}
+}
+
+// ServeHTTP routes /nitro/v1/config and /nitro/v1/stat requests
+func (sim *Simulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 4 || parts[0] != "nitro" || parts[1] != "v1" || (parts[2] != "config" && parts[2] != "stat") {
+		reply(w, http.StatusNotFound, errBadRequest, "Invalid URL", nil)
+		return
+	}
+	api, resource, name := parts[2], parts[3], ""
+	if len(parts) > 4 {
+		name, _ = url.PathUnescape(strings.Join(parts[4:], "/"))
+	}
+	if api == "config" && resource == "login" && r.Method == http.MethodPost {
+		sim.login(w, r)
+		return
+	}
+	if !sim.authorized(r) {
+		reply(w, http.StatusUnauthorized, errNoSession, "Session expired or killed. Please login again", nil)
+		return
+	}
+	switch {
+	case api == "config" && resource == "logout" && r.Method == http.MethodPost:
+		sim.logout(r)
+		reply(w, http.StatusCreated, 0, "Done", nil)
+	case api == "config" && resource == "nsrunningconfig" && r.Method == http.MethodGet:
+		sim.mu.Lock()
+		text := strings.Join(sim.lines, "\n")
+		sim.mu.Unlock()
+		reply(w, http.StatusOK, 0, "Done", map[string]interface{}{"nsrunningconfig": map[string]string{"response": text + "\nDone\n"}})
+	case r.Method == http.MethodGet:
+		sim.get(w, r, api, resource, name)
+	case api == "config" && r.Method == http.MethodDelete && name != "":
+		sim.delete(w, resource, name)
+	default:
+		reply(w, http.StatusMethodNotAllowed, errNotAllowed, "Operation not supported by the simulator", nil)
+	}
+}
+
+// login checks the credentials of the payload and opens a session
+func (sim *Simulator) login(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Login struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"login"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		reply(w, http.StatusBadRequest, errBadRequest, "Invalid login payload", nil)
+		return
+	}
+	if payload.Login.Username != sim.Username || payload.Login.Password != sim.Password {
+		reply(w, http.StatusUnauthorized, errBadLogin, "Invalid username or password", nil)
+		return
+	}
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	token := hex.EncodeToString(buf)
+	sim.mu.Lock()
+	sim.sessions[token] = true
+	sim.mu.Unlock()
+	http.SetCookie(w, &http.Cookie{Name: authCookieName, Value: token, Path: "/nitro/v1"})
+	reply(w, http.StatusCreated, 0, "Done", map[string]interface{}{"sessionid": token})
+}
+
+// logout closes the session of the request
+func (sim *Simulator) logout(r *http.Request) {
+	if cookie, err := r.Cookie(authCookieName); err == nil {
+		sim.mu.Lock()
+		delete(sim.sessions, cookie.Value)
+		sim.mu.Unlock()
+	}
+}
+
+// authorized accepts a session cookie or the X-NITRO-USER and X-NITRO-PASS headers
+func (sim *Simulator) authorized(r *http.Request) bool {
+	if cookie, err := r.Cookie(authCookieName); err == nil {
+		sim.mu.Lock()
+		defer sim.mu.Unlock()
+		return sim.sessions[cookie.Value]
+	}
+	return r.Header.Get("X-NITRO-USER") == sim.Username && r.Header.Get("X-NITRO-PASS") == sim.Password
+}
+
+// get returns the objects of a resource type, filtered by name and by the filter query parameter
+func (sim *Simulator) get(w http.ResponseWriter, r *http.Request, api string, resource string, name string) {
+	filters, err := parseFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		reply(w, http.StatusBadRequest, errBadRequest, err.Error(), nil)
+		return
+	}
+	sim.mu.Lock()
+	objects := sim.config[resource]
+	if api == "stat" {
+		objects = sim.stats(resource)
+	}
+	nameField := nsconf.NameField(resource)
+	matched := make([]nsconf.Object, 0)
+	for _, obj := range objects {
+		if name != "" && obj.String(nameField) != name {
+			continue
+		}
+		if matchFilter(obj, filters) {
+			matched = append(matched, obj)
+		}
+	}
+	sim.mu.Unlock()
+	if name != "" && len(matched) == 0 {
+		reply(w, http.StatusNotFound, errNoResource, "No such resource ["+nameField+", "+name+"]", nil)
+		return
+	}
+	reply(w, http.StatusOK, 0, "Done", map[string]interface{}{resource: matched})
+}
+
+// stats returns the fixture statistics of a resource type or derives them from its configuration
+func (sim *Simulator) stats(resource string) []nsconf.Object {
+	if objects, ok := sim.stat[resource]; ok {
+		return objects
+	}
+	nameField := nsconf.NameField(resource)
+	objects := make([]nsconf.Object, 0, len(sim.config[resource]))
+	for _, obj := range sim.config[resource] {
+		state := "UP"
+		if strings.EqualFold(obj.String("state"), "DISABLED") {
+			state = "OUT OF SERVICE"
+		}
+		if curState := obj.String("curstate"); curState != "" {
+			state = curState
+		}
+		objects = append(objects, nsconf.Object{nameField: obj.String(nameField), "state": state})
+	}
+	return objects
+}
+
+// delete removes the named object, its bindings and its lines of the running configuration
+func (sim *Simulator) delete(w http.ResponseWriter, resource string, name string) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	nameField := nsconf.NameField(resource)
+	found := false
+	for kind, objects := range sim.config {
+		if kind != resource && !strings.HasPrefix(kind, resource+"_") {
+			continue
+		}
+		kept := objects[:0]
+		for _, obj := range objects {
+			if obj.String(nsconf.NameField(kind)) == name {
+				found = found || kind == resource
+				continue
+			}
+			kept = append(kept, obj)
+		}
+		sim.config[kind] = kept
+	}
+	if !found {
+		reply(w, http.StatusNotFound, errNoResource, "No such resource ["+nameField+", "+name+"]", nil)
+		return
+	}
+	words := strings.Fields(nsconf.CLIObject(resource))
+	lines := sim.lines[:0]
+	for _, line := range sim.lines {
+		if !commandOf(nsconf.Tokenize(line), words, name) {
+			lines = append(lines, line)
+		}
+	}
+	sim.lines = lines
+	reply(w, http.StatusOK, 0, "Done", nil)
+}
+
+// commandOf reports whether a CLI command applies to the named object
+func commandOf(tokens []string, words []string, name string) bool {
+	if len(words) == 0 || len(tokens) < len(words)+2 {
+		return false
+	}
+	for i, word := range words {
+		if !strings.EqualFold(tokens[i+1], word) {
+			return false
+		}
+	}
+	return tokens[len(words)+1] == name
+}
+
+// fieldFilter is one field:value pair of the filter query parameter, /regex/ values are matched as regular expressions
+type fieldFilter struct {
+	field string
+	value string
+	regex *regexp.Regexp
+}
+
+// parseFilter reads filter=field:value,field:/regex/
+func parseFilter(query string) ([]fieldFilter, error) {
+	filters := make([]fieldFilter, 0)
+	if query == "" {
+		return filters, nil
+	}
+	for _, item := range strings.Split(query, ",") {
+		pair := strings.SplitN(item, ":", 2)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("invalid filter %v", item)
+		}
+		filter := fieldFilter{field: strings.ToLower(pair[0]), value: pair[1]}
+		if len(pair[1]) > 1 && strings.HasPrefix(pair[1], "/") && strings.HasSuffix(pair[1], "/") {
+			regex, err := regexp.Compile(strings.Trim(pair[1], "/"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %v: %v", item, err)
+			}
+			filter.regex = regex
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// matchFilter reports whether the object matches all the filters
+func matchFilter(obj nsconf.Object, filters []fieldFilter) bool {
+	for _, filter := range filters {
+		value := obj.String(filter.field)
+		if filter.regex != nil && !filter.regex.MatchString(value) {
+			return false
+		}
+		if filter.regex == nil && value != filter.value {
+			return false
+		}
+	}
+	return true
+}
+
+// reply writes a NITRO response envelope with the extra fields of body
+func reply(w http.ResponseWriter, status int, code int, message string, body map[string]interface{}) {
+	severity := "NONE"
+	if code != 0 {
+		severity = "ERROR"
+	}
+	payload := map[string]interface{}{"errorcode": code, "message": message, "severity": severity}
+	for key, value := range body {
+		payload[key] = value
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nsconf parses NetScaler running configuration (show run output) into NITRO style objects
+package nsconf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// resourceLayout describes how the CLI arguments of a NetScaler object map to NITRO fields
+type resourceLayout struct {
+	object     string
+	resource   string
+	positional []string
+	required   string
+}
+
+// layouts of the add commands, in the order of the CLI positional arguments
+var layouts = []resourceLayout{
+	{object: "lb vserver", resource: "lbvserver", positional: []string{"name", "servicetype", "ipv46", "port"}},
+	{object: "cs vserver", resource: "csvserver", positional: []string{"name", "servicetype", "ipv46", "port"}},
+	{object: "gslb vserver", resource: "gslbvserver", positional: []string{"name", "servicetype"}},
+	{object: "gslb service", resource: "gslbservice", positional: []string{"servicename", "ip", "servicetype", "port"}},
+	{object: "gslb site", resource: "gslbsite", positional: []string{"sitename", "siteipaddress"}},
+	{object: "servicegroup", resource: "servicegroup", positional: []string{"servicegroupname", "servicetype"}},
+	{object: "service", resource: "service", positional: []string{"name", "servername", "servicetype", "port"}},
+	{object: "server", resource: "server", positional: []string{"name", "ipaddress"}},
+	{object: "ssl certkey", resource: "sslcertkey", positional: []string{"certkey"}},
+	{object: "cs policy", resource: "cspolicy", positional: []string{"policyname"}},
+	{object: "cs action", resource: "csaction", positional: []string{"name"}},
+	{object: "ns ip", resource: "nsip", positional: []string{"ipaddress", "netmask"}},
+}
+
+// bindLayouts of the bind commands, the first positional argument is the bound entity.
+// Bind commands without the required option of any layout bind another kind of entity and are skipped.
+var bindLayouts = []resourceLayout{
+	{object: "servicegroup", resource: "servicegroup_servicegroupmember_binding", positional: []string{"servicegroupname", "ip", "port"}, required: "ip"},
+	{object: "lb vserver", resource: "lbvserver_servicegroup_binding", positional: []string{"name", "servicegroupname"}, required: "servicegroupname"},
+	{object: "cs vserver", resource: "csvserver_cspolicy_binding", positional: []string{"name"}, required: "policyname"},
+	{object: "ssl vserver", resource: "sslvserver_sslcertkey_binding", positional: []string{"vservername"}, required: "certkeyname"},
+	{object: "gslb vserver", resource: "gslbvserver_gslbservice_binding", positional: []string{"name"}, required: "servicename"},
+	{object: "gslb vserver", resource: "gslbvserver_domain_binding", positional: []string{"name"}, required: "domainname"},
+}
+
+// Object is a NetScaler entity with NITRO field names
+type Object map[string]interface{}
+
+// String returns a field as a string, empty when missing
+func (obj Object) String(field string) string {
+	if value, ok := obj[field]; ok && value != nil {
+		switch value.(type) {
+		case string, bool, float64, int:
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+// Config is a parsed running configuration
+type Config struct {
+	Lines   []string
+	Objects map[string][]Object
+}
+
+// Get returns the objects of a NITRO resource type
+func (conf Config) Get(resource string) []Object {
+	return conf.Objects[resource]
+}
+
+// NameField returns the NITRO field holding the name of a resource type
+func NameField(resource string) string {
+	for _, layout := range append(append([]resourceLayout{}, layouts...), bindLayouts...) {
+		if layout.resource == resource {
+			return layout.positional[0]
+		}
+	}
+	return "name"
+}
+
+// Resources returns the NITRO resource types of the known add commands
+func Resources() []string {
+	resources := make([]string, 0, len(layouts))
+	for _, layout := range layouts {
+		resources = append(resources, layout.resource)
+	}
+	return resources
+}
+
+// CLIObject returns the CLI object words of a resource type, such as lb vserver for lbvserver
+func CLIObject(resource string) string {
+	for _, layout := range append(append([]resourceLayout{}, layouts...), bindLayouts...) {
+		if layout.resource == resource {
+			return layout.object
+		}
+	}
+	return ""
+}
+
+// Parse reads show run output, add and bind commands of known objects are converted to NITRO objects
+func Parse(text string) Config {
+	conf := Config{Objects: make(map[string][]Object)}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "Done" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		conf.Lines = append(conf.Lines, line)
+		tokens := Tokenize(line)
+		if len(tokens) < 3 {
+			continue
+		}
+		var table []resourceLayout
+		switch strings.ToLower(tokens[0]) {
+		case "add":
+			table = layouts
+		case "bind":
+			table = bindLayouts
+		default:
+			continue
+		}
+		for _, layout := range table {
+			words := strings.Fields(layout.object)
+			if len(tokens) <= len(words) || !matchWords(tokens[1:1+len(words)], words) {
+				continue
+			}
+			obj := parseArgs(tokens[1+len(words):], layout.positional)
+			// Another layout of the same object may bind this kind of entity
+			if layout.required != "" && obj.String(layout.required) == "" {
+				continue
+			}
+			conf.Objects[layout.resource] = append(conf.Objects[layout.resource], obj)
+			break
+		}
+	}
+	return conf
+}
+
+// Command renders an add command for a NITRO object, the reverse of Parse
+func Command(resource string, obj Object) string {
+	for _, layout := range layouts {
+		if layout.resource != resource {
+			continue
+		}
+		parts := []string{"add", layout.object}
+		used := make(map[string]bool)
+		for _, field := range layout.positional {
+			if value := obj.String(field); value != "" {
+				parts = append(parts, quote(value))
+				used[field] = true
+			}
+		}
+		fields := make([]string, 0, len(obj))
+		for field := range obj {
+			if !used[field] {
+				fields = append(fields, field)
+			}
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			if value := obj.String(field); value != "" {
+				parts = append(parts, "-"+field, quote(value))
+			}
+		}
+		return strings.Join(parts, " ")
+	}
+	return ""
+}
+
+// Tokenize splits a CLI command in words, double quoted words may contain spaces
+func Tokenize(line string) []string {
+	tokens := make([]string, 0)
+	var current strings.Builder
+	inQuote, escaped, started := false, false, false
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && inQuote:
+			escaped = true
+		case r == '"':
+			inQuote = !inQuote
+			started = true
+		case (r == ' ' || r == '\t') && !inQuote:
+			if started {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// parseArgs maps positional arguments and -option value pairs to NITRO fields
+func parseArgs(args []string, positional []string) Object {
+	obj := make(Object)
+	pos := 0
+	for i := 0; i < len(args); i++ {
+		if strings.HasPrefix(args[i], "-") && len(args[i]) > 1 && !isNumber(args[i]) {
+			field := strings.ToLower(strings.TrimPrefix(args[i], "-"))
+			if i+1 < len(args) && !(strings.HasPrefix(args[i+1], "-") && !isNumber(args[i+1])) {
+				obj[field] = args[i+1]
+				i++
+			} else {
+				obj[field] = "true"
+			}
+			continue
+		}
+		if pos < len(positional) {
+			obj[positional[pos]] = args[i]
+			pos++
+		}
+	}
+	return obj
+}
+
+// matchWords compares CLI object words case insensitively
+func matchWords(tokens []string, words []string) bool {
+	for i, word := range words {
+		if !strings.EqualFold(tokens[i], word) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNumber reports whether a token such as -1 is a negative number rather than an option
+func isNumber(token string) bool {
+	for i, r := range token {
+		if !(r >= '0' && r <= '9') && !(i == 0 && r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// quote wraps CLI values containing spaces in double quotes
+func quote(value string) string {
+	if strings.ContainsAny(value, " \t") || value == "" {
+		return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+	}
+	return value
+}
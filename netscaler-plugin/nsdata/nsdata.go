@@ -0,0 +1,109 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nsdata reads NetScaler entities either directly over NITRO or from the running configuration
+// printed by the ingress controller, so that subcommands can inspect them the same way.
+package nsdata
+
+import (
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/kubectl"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nitro"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/nsconf"
+)
+
+// StateUnknown is the state of entities read from the running configuration
+const StateUnknown = "--"
+
+// Snapshot is the configuration of NetScaler entities and their state when it is known
+type Snapshot struct {
+	Config nsconf.Config
+	// Live is true when the entities were read over NITRO and their state is known
+	Live  bool
+	state map[string]map[string]string
+}
+
+// Load reads the given resource types from the --nitro-url endpoint when given,
+// otherwise from the running configuration of the controller pod
+func Load(flags *genericclioptions.ConfigFlags, pod *apiv1.Pod, container string, resources ...string) (Snapshot, error) {
+	if nitro.URL == "" {
+		op, err := kubectl.NetScalerConf(flags, pod, container)
+		if err != nil {
+			return Snapshot{}, err
+		}
+		return Snapshot{Config: nsconf.Parse(op)}, nil
+	}
+	client := nitro.NewClient(nitro.URL, "", "")
+	if err := client.Login(); err != nil {
+		return Snapshot{}, err
+	}
+	defer client.Logout()
+	snapshot := Snapshot{
+		Config: nsconf.Config{Objects: make(map[string][]nsconf.Object)},
+		Live:   true,
+		state:  make(map[string]map[string]string),
+	}
+	for _, resource := range resources {
+		objects, err := client.Get(resource, "")
+		if err != nil {
+			return snapshot, err
+		}
+		snapshot.Config.Objects[resource] = objects
+		if strings.HasSuffix(resource, "_binding") {
+			continue
+		}
+		// Some resource types have no statistics, their state stays unknown
+		stats, err := client.Stat(resource, "")
+		if err != nil {
+			continue
+		}
+		states := make(map[string]string)
+		nameField := nsconf.NameField(resource)
+		for _, stat := range stats {
+			states[stat.String(nameField)] = stat.String("state")
+		}
+		snapshot.state[resource] = states
+	}
+	return snapshot, nil
+}
+
+// Get returns the objects of a resource type
+func (snapshot Snapshot) Get(resource string) []nsconf.Object {
+	return snapshot.Config.Get(resource)
+}
+
+// Find returns the object of a resource type with the given name, nil when missing
+func (snapshot Snapshot) Find(resource string, name string) nsconf.Object {
+	nameField := nsconf.NameField(resource)
+	for _, obj := range snapshot.Config.Get(resource) {
+		if obj.String(nameField) == name {
+			return obj
+		}
+	}
+	return nil
+}
+
+// State returns the state of an entity, the configured DISABLED state or StateUnknown when it is not known
+func (snapshot Snapshot) State(resource string, name string) string {
+	if state, ok := snapshot.state[resource][name]; ok && state != "" {
+		return state
+	}
+	if obj := snapshot.Find(resource, name); obj != nil && strings.EqualFold(obj.String("state"), "DISABLED") {
+		return "DISABLED"
+	}
+	return StateUnknown
+}
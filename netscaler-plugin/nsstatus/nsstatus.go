@@ -0,0 +1,182 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nsstatus parses the NetScaler entity status table printed by the status subcommand
+package nsstatus
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Column names of the status table
+const (
+	ColNamespace = "NAMESPACE"
+	ColIngress   = "INGRESS"
+	ColPort      = "PORT"
+	ColResource  = "RESOURCE"
+	ColName      = "NAME"
+	ColStatus    = "STATUS"
+)
+
+// Resource values of the status table
+const (
+	ResourceLoadBalancer    = "Load Balancer"
+	ResourceService         = "Service"
+	ResourceServiceEndpoint = "Service Endpoint"
+	ResourceListener        = "Listener"
+)
+
+var columnSeparator = regexp.MustCompile(`\S+( \S+)*`)
+
+// column is a header cell and the offset where its values start
+type column struct {
+	name  string
+	start int
+}
+
+// Table is the parsed status output, the title line and header are kept to print filtered tables
+type Table struct {
+	Title   string
+	Header  string
+	Columns []string
+	Rows    []Row
+}
+
+// Row is one NetScaler entity of the status table
+type Row struct {
+	Line   string
+	Values map[string]string
+}
+
+// Get returns the value of a column, -- when the column is missing
+func (row Row) Get(col string) string {
+	if value, ok := row.Values[col]; ok && value != "" {
+		return value
+	}
+	return "--"
+}
+
+// Value returns the value of a column, empty when the column is missing or --
+func (row Row) Value(col string) string {
+	if value := row.Get(col); value != "--" {
+		return value
+	}
+	return ""
+}
+
+// Up reports whether the status of the row is up
+func (row Row) Up() bool {
+	return strings.EqualFold(row.Get(ColStatus), "up")
+}
+
+// Parse reads the fixed width status table, columns are located by the offsets of the header cells
+func Parse(out string) Table {
+	table := Table{}
+	var columns []column
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if columns == nil {
+			if strings.HasPrefix(strings.TrimSpace(line), ColNamespace) {
+				table.Header = line
+				for _, loc := range columnSeparator.FindAllStringIndex(line, -1) {
+					columns = append(columns, column{name: line[loc[0]:loc[1]], start: loc[0]})
+					table.Columns = append(table.Columns, line[loc[0]:loc[1]])
+				}
+			} else if table.Title == "" {
+				table.Title = line
+			}
+			continue
+		}
+		row := Row{Line: line, Values: make(map[string]string)}
+		for i, col := range columns {
+			if col.start >= len(line) {
+				break
+			}
+			end := len(line)
+			if i+1 < len(columns) && columns[i+1].start < end {
+				end = columns[i+1].start
+			}
+			row.Values[col.name] = strings.TrimSpace(line[col.start:end])
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+// ServiceGroup is a Service row of the status table with the Service Endpoint rows following it
+type ServiceGroup struct {
+	Namespace string
+	Ingress   string
+	Port      string
+	Name      string
+	Members   []Row
+}
+
+// ServiceGroups groups the Service Endpoint rows by the Service row of the same namespace, ingress and port
+func (table Table) ServiceGroups() []*ServiceGroup {
+	groups := make([]*ServiceGroup, 0)
+	current := make(map[string]*ServiceGroup)
+	for _, row := range table.Rows {
+		key := row.Get(ColNamespace) + "/" + row.Get(ColIngress) + "/" + row.Get(ColPort)
+		switch row.Get(ColResource) {
+		case ResourceService:
+			group := &ServiceGroup{Namespace: row.Value(ColNamespace), Ingress: row.Value(ColIngress), Port: row.Value(ColPort), Name: row.Value(ColName)}
+			groups = append(groups, group)
+			current[key] = group
+		case ResourceServiceEndpoint:
+			if group, ok := current[key]; ok {
+				group.Members = append(group.Members, row)
+			}
+		}
+	}
+	return groups
+}
+
+// FilterIngress keeps the rows of the given ingress
+func (table Table) FilterIngress(ing string) Table {
+	filtered := Table{Title: table.Title, Header: table.Header, Columns: table.Columns}
+	for _, row := range table.Rows {
+		if row.Get(ColIngress) == ing {
+			filtered.Rows = append(filtered.Rows, row)
+		}
+	}
+	return filtered
+}
+
+// String prints the table as it was displayed by the ingress controller
+func (table Table) String() string {
+	lines := make([]string, 0, len(table.Rows)+2)
+	if table.Title != "" {
+		lines = append(lines, table.Title)
+	}
+	if table.Header != "" {
+		lines = append(lines, table.Header)
+	}
+	for _, row := range table.Rows {
+		lines = append(lines, row.Line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JSON returns the rows as a list of column to value maps
+func (table Table) JSON() []map[string]string {
+	rows := make([]map[string]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		rows = append(rows, row.Values)
+	}
+	return rows
+}
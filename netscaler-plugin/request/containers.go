@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package request
+
+import (
+	"fmt"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/bundle"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/kubectl"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// roleSignature lists the evidence identifying the role of a container
+type roleSignature struct {
+	images []string
+	// entrypoints are searched in the command and arguments of the container
+	entrypoints []string
+	envs        []string
+}
+
+var (
+	cicRole = roleSignature{
+		images:      []string{"ingress-controller", "nsic"},
+		entrypoints: []string{"triton", constant.IngressClassesArg, constant.NodeWatchArg},
+		envs:        []string{"NS_DEPLOYMENT_MODE", constant.PrefixEnv, constant.IngressClassesEnv},
+	}
+	// EULA is also set on the ingress controller container and is not evidence of NetScaler CPX
+	cpxRole = roleSignature{
+		images: []string{"cpx"},
+		envs:   []string{"CPX_CORES"},
+	}
+)
+
+// evidence returns why a container matches the signature, empty when it does not
+func (sig roleSignature) evidence(container apiv1.Container) []string {
+	found := make([]string, 0)
+	image := strings.ToLower(imageName(container.Image))
+	for _, keyword := range sig.images {
+		if strings.Contains(image, keyword) {
+			found = append(found, "image "+imageName(container.Image))
+			break
+		}
+	}
+	entrypoint := strings.Join(append(append([]string{}, container.Command...), container.Args...), " ")
+	for _, keyword := range sig.entrypoints {
+		if strings.Contains(entrypoint, keyword) {
+			found = append(found, "entrypoint "+keyword)
+			break
+		}
+	}
+	for _, env := range container.Env {
+		for _, name := range sig.envs {
+			if env.Name == name {
+				found = append(found, "env "+name)
+			}
+		}
+	}
+	return found
+}
+
+// isExporter reports whether the container runs the NetScaler metrics exporter
+func isExporter(container apiv1.Container) bool {
+	return strings.Contains(strings.ToLower(imageName(container.Image)), "exporter")
+}
+
+// chooseContainers returns the ingress controller and NetScaler CPX containers of the pod with the reason of
+// the choice. --container and --cpx-container take precedence, otherwise the containers with the most evidence
+// are chosen, the ingress controller first. The VERSION file of the ingress controller decides when the evidence does not.
+func chooseContainers(flags *genericclioptions.ConfigFlags, pod apiv1.Pod, selector PodSelector) (string, string, string, error) {
+	names := make([]string, 0, len(pod.Spec.Containers))
+	for _, container := range pod.Spec.Containers {
+		names = append(names, container.Name)
+	}
+	for _, name := range []string{selector.Container, selector.CPXContainer} {
+		if name != "" && !util.ContainsString(names, name) {
+			return "", "", "", fmt.Errorf("container %v not found in pod %v, its containers are %v", name, pod.Name, strings.Join(names, ", "))
+		}
+	}
+	reasons := make([]string, 0)
+	cicContainer, cpxContainer := selector.Container, selector.CPXContainer
+	// The ingress controller is detected first so that its container is never taken for NetScaler CPX
+	if cicContainer != "" {
+		reasons = append(reasons, "ingress controller container "+cicContainer+" given with --container")
+	} else {
+		cicContainer, reasons = bestContainer(pod, cicRole, cpxContainer, "ingress controller", reasons)
+	}
+	if cpxContainer != "" {
+		reasons = append(reasons, "NetScaler CPX container "+cpxContainer+" given with --cpx-container")
+	} else {
+		cpxContainer, reasons = bestContainer(pod, cpxRole, cicContainer, "NetScaler CPX", reasons)
+	}
+	if cicContainer == "" && !bundle.Enabled() {
+		cicContainer, reasons = versionContainer(flags, pod, cpxContainer, reasons)
+	}
+	if cicContainer == "" {
+		reasons = append(reasons, "no ingress controller container detected, the default container of the pod is used")
+	}
+	return cicContainer, cpxContainer, strings.Join(reasons, "; "), nil
+}
+
+// versionContainer returns the first container other than skip shipping the VERSION file of the ingress
+// controller image, and appends the reason
+func versionContainer(flags *genericclioptions.ConfigFlags, pod apiv1.Pod, skip string, reasons []string) (string, []string) {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == skip || isExporter(container) {
+			continue
+		}
+		args := []string{"-c", container.Name, "--", "cat", constant.VersionFile}
+		if _, err := kubectl.PodExecString(flags, &pod, args); err == nil {
+			return container.Name, append(reasons, "ingress controller container "+container.Name+": "+constant.VersionFile+" present")
+		}
+	}
+	return "", reasons
+}
+
+// bestContainer returns the container other than skip with the most evidence of the role, and appends the reason
+func bestContainer(pod apiv1.Pod, sig roleSignature, skip string, role string, reasons []string) (string, []string) {
+	best, bestEvidence := "", []string{}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == skip || isExporter(container) {
+			continue
+		}
+		if evidence := sig.evidence(container); len(evidence) > len(bestEvidence) {
+			best, bestEvidence = container.Name, evidence
+		}
+	}
+	if best != "" {
+		reasons = append(reasons, role+" container "+best+": "+strings.Join(bestEvidence, ", "))
+	}
+	return best, reasons
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package request
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// Controller describes a NetScaler controller pod found in the cluster
+type Controller struct {
+	Pod       apiv1.Pod `json:"-"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Mode      string    `json:"mode"`
+	Version   string    `json:"version"`
+	Target    string    `json:"netscaler"`
+	Health    string    `json:"health"`
+	// Classes are the ingress classes of an ingress controller, empty when it handles any class
+	Classes []string `json:"ingressClasses,omitempty"`
+	// Leader is the leader-election lock held by the pod or by another replica, nil when none is found
+	Leader *Leader `json:"leader,omitempty"`
+}
+
+// controllerSignature maps keywords found in images and labels to a controller kind
+type controllerSignature struct {
+	kind     string
+	keywords []string
+	envs     []string
+}
+
+// controllerSignatures is evaluated in order, more specific controllers are listed first
+var controllerSignatures = []controllerSignature{
+	{kind: constant.KindGSLB, keywords: []string{"gslb"}, envs: []string{"LOCAL_REGION", "SITENAMES"}},
+	{kind: constant.KindIPAM, keywords: []string{"ipam"}, envs: []string{"VIP_RANGE"}},
+	{kind: constant.KindGateway, keywords: []string{"gateway"}, envs: []string{"GATEWAY_CONTROLLER_NAME"}},
+	{kind: constant.KindCIC, keywords: []string{"k8s-ingress-controller", "ingress-controller"}, envs: []string{"NS_DEPLOYMENT_MODE"}},
+	{kind: constant.KindCPX, keywords: []string{"cpx"}, envs: []string{"CPX_CORES"}},
+}
+
+// DiscoverControllers lists the NetScaler controller pods in the namespace of flags, or in all namespaces
+func (kClient *K8sClient) DiscoverControllers(flags *genericclioptions.ConfigFlags, allNamespaces bool) ([]Controller, error) {
+	namespace := ""
+	if !allNamespaces {
+		ns, err := util.GetNamespace(flags)
+		if err != nil {
+			return nil, err
+		}
+		namespace = ns
+	}
+	pods, err := kClient.K8sClient.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	controllers := make([]Controller, 0)
+	for _, pod := range pods.Items {
+		if ctrl, ok := ClassifyPod(pod); ok {
+			controllers = append(controllers, ctrl)
+		}
+	}
+	return controllers, nil
+}
+
+// ClassifyPod reports whether the pod runs a NetScaler controller and describes it
+func ClassifyPod(pod apiv1.Pod) (Controller, bool) {
+	kind, image := "", ""
+	for _, sig := range controllerSignatures {
+		if img, ok := podMatchesSignature(pod, sig); ok {
+			kind, image = sig.kind, img
+			break
+		}
+	}
+	if kind == "" {
+		return Controller{}, false
+	}
+	ctrl := Controller{
+		Pod:       pod,
+		Namespace: pod.Namespace,
+		Name:      pod.Name,
+		Kind:      kind,
+		Mode:      constant.ModeStandalone,
+		Version:   imageTag(image),
+		Target:    "--",
+		Health:    podHealth(pod),
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, env := range container.Env {
+			if env.Name == "NS_DEPLOYMENT_MODE" && env.Value == "SIDECAR" {
+				ctrl.Mode = constant.ModeSidecar
+			}
+			if env.Name == "NS_IP" && env.Value != "" && ctrl.Target == "--" {
+				ctrl.Target = env.Value
+			}
+		}
+	}
+	if kind == constant.KindCPX {
+		ctrl.Mode = constant.ModeCPX
+	}
+	if kind == constant.KindCIC {
+		ctrl.Classes = ControllerClasses(pod)
+	}
+	if ctrl.Mode != constant.ModeStandalone {
+		ctrl.Target = constant.LocalCPXTarget
+	}
+	return ctrl, true
+}
+
+// ControllerPrefix returns the NS_APPS_NAME_PREFIX of the ingress controller, k8s when it is not set. The prefix
+// starts the names of the NetScaler entities the controller creates.
+func ControllerPrefix(pod apiv1.Pod) string {
+	prefix := constant.DefaultPrefix
+	for _, container := range pod.Spec.Containers {
+		for _, env := range container.Env {
+			if env.Name == constant.PrefixEnv && env.Value != "" {
+				prefix = env.Value
+			}
+		}
+	}
+	return prefix
+}
+
+// podMatchesSignature returns the image of the first container matching the signature. Only the containers
+// running a NetScaler image are considered, keywords such as ingress-controller or gateway are also found in the
+// images of other vendors.
+func podMatchesSignature(pod apiv1.Pod, sig controllerSignature) (string, bool) {
+	labels := make([]string, 0, len(pod.Labels))
+	for key, value := range pod.Labels {
+		if strings.HasPrefix(key, "app") {
+			labels = append(labels, strings.ToLower(value))
+		}
+	}
+	netscalerImage := ""
+	for _, container := range pod.Spec.Containers {
+		if !isNetScalerImage(container.Image) {
+			continue
+		}
+		image := strings.ToLower(imageName(container.Image))
+		for _, keyword := range sig.keywords {
+			if strings.Contains(image, keyword) {
+				return container.Image, true
+			}
+		}
+		if netscalerImage == "" {
+			netscalerImage = container.Image
+		}
+	}
+	if netscalerImage == "" {
+		return "", false
+	}
+	for _, container := range pod.Spec.Containers {
+		if !isNetScalerImage(container.Image) {
+			continue
+		}
+		for _, env := range container.Env {
+			for _, name := range sig.envs {
+				if env.Name == name {
+					return container.Image, true
+				}
+			}
+		}
+	}
+	for _, label := range labels {
+		for _, keyword := range sig.keywords {
+			if strings.Contains(label, keyword) {
+				return netscalerImage, true
+			}
+		}
+	}
+	return "", false
+}
+
+// isNetScalerImage reports whether the registry, repository or name of a container image reference is the one of
+// a NetScaler or Citrix image
+func isNetScalerImage(image string) bool {
+	repository := strings.ToLower(strings.SplitN(image, "@", 2)[0])
+	if idx := strings.LastIndex(repository, ":"); idx > strings.LastIndex(repository, "/") {
+		repository = repository[:idx]
+	}
+	return strings.Contains(repository, "citrix") || strings.Contains(repository, "netscaler")
+}
+
+// imageName strips the registry, tag and digest from a container image reference
+func imageName(image string) string {
+	image = strings.SplitN(image, "@", 2)[0]
+	if idx := strings.LastIndex(image, "/"); idx >= 0 {
+		image = image[idx+1:]
+	}
+	return strings.SplitN(image, ":", 2)[0]
+}
+
+// imageTag returns the tag of a container image reference or -- when it is not tagged
+func imageTag(image string) string {
+	image = strings.SplitN(image, "@", 2)[0]
+	if idx := strings.LastIndex(image, "/"); idx >= 0 {
+		image = image[idx+1:]
+	}
+	parts := strings.SplitN(image, ":", 2)
+	if len(parts) < 2 || parts[1] == "" {
+		return "--"
+	}
+	return parts[1]
+}
+
+// podHealth summarises readiness and restarts of a pod
+func podHealth(pod apiv1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+	if pod.Status.Phase != apiv1.PodRunning {
+		return string(pod.Status.Phase)
+	}
+	ready, total, restarts := PodReadiness(pod)
+	health := "Healthy"
+	if ready != total {
+		health = "NotReady"
+	}
+	health = fmt.Sprintf("%v (%v/%v ready", health, ready, total)
+	if restarts > 0 {
+		health = fmt.Sprintf("%v, %v restarts", health, restarts)
+	}
+	return health + ")"
+}
+
+// GetDiscoveredPod finds the controller pod of the given kind, the ingress controller when empty, when no selector
+// is provided. The namespace given with -n is searched, otherwise all namespaces. The leader of the replicas is
+// preferred unless --any-replica is set.
+func (kClient *K8sClient) GetDiscoveredPod(flags *genericclioptions.ConfigFlags, selector PodSelector) (apiv1.Pod, error) {
+	kind := selector.Kind
+	if kind == "" {
+		kind = constant.KindCIC
+	}
+	allNamespaces := flags.Namespace == nil || *flags.Namespace == ""
+	controllers, err := kClient.DiscoverControllers(flags, allNamespaces)
+	if err != nil {
+		return apiv1.Pod{}, err
+	}
+	pods := make([]apiv1.Pod, 0)
+	for _, ctrl := range controllers {
+		if ctrl.Kind == kind {
+			pods = append(pods, ctrl.Pod)
+		}
+	}
+	if leader, ok := kClient.leaderPod(flags, pods, selector); ok {
+		return leader, nil
+	}
+	pods = preferredPods(pods)
+	what := "ingress controller"
+	if kind != constant.KindCIC {
+		what = kind + " controller"
+	}
+	if len(pods) == 0 {
+		return apiv1.Pod{}, fmt.Errorf("no running %v found, please provide either label (-l, --label), deployment (--deployment) or pod (--pod ) as a selector in the command", what)
+	}
+	return pickPod(pods, selector.Pick, what+" discovery")
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package request
+
+import (
+	"context"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+)
+
+// netscalerIngressControllers are the spec.controller values of the IngressClasses of the ingress controller
+var netscalerIngressControllers = []string{"citrix.com/ingress-controller", "netscaler.com/ingress-controller"}
+
+// IngressOwnership tells which NetScaler ingress controllers claim an Ingress
+type IngressOwnership struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Class     string `json:"class"`
+	// Controller is the spec.controller of the IngressClass of the Ingress, empty when it is not known
+	Controller string   `json:"controller,omitempty"`
+	ClaimedBy  []string `json:"claimedBy"`
+}
+
+// Unclaimed reports whether no NetScaler ingress controller claims an Ingress which is not of the
+// IngressClass of another ingress controller
+func (owner IngressOwnership) Unclaimed() bool {
+	return len(owner.ClaimedBy) == 0 && (owner.Controller == "" || containsFold(netscalerIngressControllers, owner.Controller))
+}
+
+// ControllerClasses returns the ingress classes given to the ingress controller with the --ingress-classes
+// argument or the INGRESS_CLASSES environment variable, empty when the controller handles any class
+func ControllerClasses(pod apiv1.Pod) []string {
+	classes := make([]string, 0)
+	for _, container := range pod.Spec.Containers {
+		args := make([]string, 0)
+		for _, arg := range append(append([]string{}, container.Command...), container.Args...) {
+			args = append(args, strings.Fields(arg)...)
+		}
+		for i := 0; i < len(args); i++ {
+			if strings.HasPrefix(args[i], constant.IngressClassesArg+"=") {
+				classes = append(classes, splitClasses(strings.TrimPrefix(args[i], constant.IngressClassesArg+"="))...)
+				continue
+			}
+			if args[i] != constant.IngressClassesArg {
+				continue
+			}
+			// The argument takes the following values up to the next option
+			for i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				i++
+				classes = append(classes, splitClasses(args[i])...)
+			}
+		}
+		for _, env := range container.Env {
+			if env.Name == constant.IngressClassesEnv {
+				classes = append(classes, splitClasses(env.Value)...)
+			}
+		}
+	}
+	return classes
+}
+
+// splitClasses splits a comma or space separated list of ingress classes
+func splitClasses(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+// IngressClassName returns the class of an Ingress from spec.ingressClassName or the kubernetes.io/ingress.class annotation
+func IngressClassName(ingress networking.Ingress) string {
+	if ingress.Spec.IngressClassName != nil && *ingress.Spec.IngressClassName != "" {
+		return *ingress.Spec.IngressClassName
+	}
+	return ingress.Annotations[constant.ClassAnnotation]
+}
+
+// GetIngressClasses returns the IngressClass objects of the cluster
+func (kClient *K8sClient) GetIngressClasses(flags *genericclioptions.ConfigFlags) ([]networking.IngressClass, error) {
+	classes, err := kClient.K8sClient.NetworkingV1().IngressClasses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return make([]networking.IngressClass, 0), err
+	}
+	return classes.Items, nil
+}
+
+// OwnsIngress reports whether an ingress controller started with classes handles an Ingress. A controller
+// without classes handles the Ingresses of the IngressClasses of NetScaler and the Ingresses without class,
+// unless another controller is the default IngressClass.
+func OwnsIngress(classes []string, ingressClasses []networking.IngressClass, ingress networking.Ingress) bool {
+	class := IngressClassName(ingress)
+	if class == "" {
+		for _, ingressClass := range ingressClasses {
+			if ingressClass.Annotations[networking.AnnotationIsDefaultIngressClass] == "true" {
+				if len(classes) > 0 {
+					return containsFold(classes, ingressClass.Name)
+				}
+				return isNetScalerClass(ingressClass)
+			}
+		}
+		return len(classes) == 0
+	}
+	if len(classes) > 0 {
+		return containsFold(classes, class)
+	}
+	for _, ingressClass := range ingressClasses {
+		if ingressClass.Name == class {
+			return isNetScalerClass(ingressClass)
+		}
+	}
+	return false
+}
+
+// isNetScalerClass reports whether the IngressClass is implemented by the NetScaler ingress controller
+func isNetScalerClass(ingressClass networking.IngressClass) bool {
+	return containsFold(netscalerIngressControllers, ingressClass.Spec.Controller)
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetControllerIngresses returns the Ingresses of the namespace, all namespaces if it is empty, handled by the
+// ingress controller running in pod
+func (kClient *K8sClient) GetControllerIngresses(flags *genericclioptions.ConfigFlags, pod apiv1.Pod, namespace string) ([]networking.Ingress, error) {
+	ingresses, err := kClient.GetIngressDefinitions(flags, namespace)
+	if err != nil {
+		return ingresses, err
+	}
+	ingressClasses, err := kClient.GetIngressClasses(flags)
+	if err != nil {
+		return ingresses, err
+	}
+	classes := ControllerClasses(pod)
+	owned := make([]networking.Ingress, 0, len(ingresses))
+	for _, ingress := range ingresses {
+		if OwnsIngress(classes, ingressClasses, ingress) {
+			owned = append(owned, ingress)
+		}
+	}
+	return owned, nil
+}
+
+// GetIngressOwnership returns the ingress controllers among controllers claiming each Ingress of the namespace,
+// all namespaces if it is empty. Ingresses which are not claimed have no ClaimedBy entry.
+func (kClient *K8sClient) GetIngressOwnership(flags *genericclioptions.ConfigFlags, namespace string, controllers []Controller) ([]IngressOwnership, error) {
+	ingresses, err := kClient.GetIngressDefinitions(flags, namespace)
+	if err != nil {
+		return nil, err
+	}
+	ingressClasses, err := kClient.GetIngressClasses(flags)
+	if err != nil {
+		return nil, err
+	}
+	ownership := make([]IngressOwnership, 0, len(ingresses))
+	for _, ingress := range ingresses {
+		owner := IngressOwnership{Namespace: ingress.Namespace, Name: ingress.Name, Class: IngressClassName(ingress), ClaimedBy: make([]string, 0)}
+		for _, ingressClass := range ingressClasses {
+			isDefault := ingressClass.Annotations[networking.AnnotationIsDefaultIngressClass] == "true"
+			if ingressClass.Name == owner.Class || (owner.Class == "" && isDefault) {
+				owner.Controller = ingressClass.Spec.Controller
+			}
+		}
+		for _, ctrl := range controllers {
+			if ctrl.Kind == constant.KindCIC && OwnsIngress(ctrl.Classes, ingressClasses, ingress) {
+				owner.ClaimedBy = append(owner.ClaimedBy, ctrl.Namespace+"/"+ctrl.Name)
+			}
+		}
+		ownership = append(ownership, owner)
+	}
+	return ownership, nil
+}
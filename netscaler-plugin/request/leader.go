@@ -0,0 +1,195 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// Leader is the holder of a leader-election Lease or ConfigMap lock
+type Leader struct {
+	// Lock is the kind, namespace and name of the lock, such as Lease/netscaler/cic-leader
+	Lock        string    `json:"lock"`
+	Identity    string    `json:"identity"`
+	RenewTime   time.Time `json:"renewTime"`
+	Transitions int32     `json:"transitions"`
+	// Expired is set when the holder did not renew the lock within its duration
+	Expired bool `json:"expired,omitempty"`
+}
+
+// String describes the lock, its renew time and transitions
+func (leader Leader) String() string {
+	return fmt.Sprintf("%v (%v, %v transitions)", leader.Lock, leader.Renewed(), leader.Transitions)
+}
+
+// Renewed tells how long ago the lock was renewed and whether it expired
+func (leader Leader) Renewed() string {
+	renewed := "never renewed"
+	if !leader.RenewTime.IsZero() {
+		renewed = "renewed " + duration.HumanDuration(time.Since(leader.RenewTime)) + " ago"
+	}
+	if leader.Expired {
+		renewed += ", expired"
+	}
+	return renewed
+}
+
+// HeldBy reports whether the pod holds the lock, leader election identities are the pod name optionally
+// followed by an underscore and a unique suffix
+func (leader Leader) HeldBy(pod apiv1.Pod) bool {
+	return leader.Identity == pod.Name || strings.HasPrefix(leader.Identity, pod.Name+"_")
+}
+
+// GetLeaders returns the holders of the leader-election Leases and ConfigMaps of the namespace
+func (kClient *K8sClient) GetLeaders(flags *genericclioptions.ConfigFlags, namespace string) ([]Leader, error) {
+	leaders := make([]Leader, 0)
+	leases, err := kClient.K8sClient.CoordinationV1().Leases(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return leaders, err
+	}
+	for _, lease := range leases.Items {
+		if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" {
+			continue
+		}
+		leader := Leader{Lock: "Lease/" + lease.Namespace + "/" + lease.Name, Identity: *lease.Spec.HolderIdentity}
+		if lease.Spec.RenewTime != nil {
+			leader.RenewTime = lease.Spec.RenewTime.Time
+		}
+		if lease.Spec.LeaseTransitions != nil {
+			leader.Transitions = *lease.Spec.LeaseTransitions
+		}
+		if lease.Spec.LeaseDurationSeconds != nil {
+			leader.Expired = expired(leader.RenewTime, int(*lease.Spec.LeaseDurationSeconds))
+		}
+		leaders = append(leaders, leader)
+	}
+	configMaps, err := kClient.K8sClient.CoreV1().ConfigMaps(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return leaders, err
+	}
+	for _, configMap := range configMaps.Items {
+		annotation, ok := configMap.Annotations[resourcelock.LeaderElectionRecordAnnotationKey]
+		if !ok {
+			continue
+		}
+		var record resourcelock.LeaderElectionRecord
+		if err = json.Unmarshal([]byte(annotation), &record); err != nil || record.HolderIdentity == "" {
+			continue
+		}
+		leaders = append(leaders, Leader{
+			Lock:        "ConfigMap/" + configMap.Namespace + "/" + configMap.Name,
+			Identity:    record.HolderIdentity,
+			RenewTime:   record.RenewTime.Time,
+			Transitions: int32(record.LeaderTransitions),
+			Expired:     expired(record.RenewTime.Time, record.LeaseDurationSeconds),
+		})
+	}
+	return leaders, nil
+}
+
+// expired reports whether a lock renewed at renewTime lapsed after the lease duration
+func expired(renewTime time.Time, leaseDurationSeconds int) bool {
+	return !renewTime.IsZero() && leaseDurationSeconds > 0 && time.Since(renewTime) > time.Duration(leaseDurationSeconds)*time.Second
+}
+
+// PodLeader returns the lock held by the pod, or by another replica of the same controller when the pod is a
+// standby
+func (kClient *K8sClient) PodLeader(flags *genericclioptions.ConfigFlags, pod apiv1.Pod) (Leader, bool, error) {
+	leaders, err := kClient.GetLeaders(flags, pod.Namespace)
+	if err != nil {
+		return Leader{}, false, err
+	}
+	pods, err := kClient.K8sClient.CoreV1().Pods(pod.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return Leader{}, false, err
+	}
+	leader, ok := ReplicaLeader(pod, pods.Items, leaders)
+	return leader, ok, nil
+}
+
+// ReplicaLeader returns the unexpired lock held by the pod, otherwise by one of the pods sharing its controller
+// owner, otherwise the expired lock last held by the pod
+func ReplicaLeader(pod apiv1.Pod, pods []apiv1.Pod, leaders []Leader) (Leader, bool) {
+	replicas := []apiv1.Pod{pod}
+	if owner := metav1.GetControllerOf(&pod); owner != nil {
+		for _, replica := range pods {
+			if ref := metav1.GetControllerOf(&replica); ref != nil && ref.UID == owner.UID && replica.Name != pod.Name {
+				replicas = append(replicas, replica)
+			}
+		}
+	}
+	for _, replica := range replicas {
+		for _, leader := range leaders {
+			if !leader.Expired && leader.HeldBy(replica) {
+				return leader, true
+			}
+		}
+	}
+	for _, leader := range leaders {
+		if leader.HeldBy(pod) {
+			return leader, true
+		}
+	}
+	return Leader{}, false
+}
+
+// leaderPod returns the replica holding an unexpired leader-election lock among the running pods matched by a
+// selector, unless --any-replica is set. The leader is only chosen when the pods are in one namespace and a
+// single one of them holds a lock, pods of several controllers are left to --pick. Locks which cannot be read,
+// such as without permission on Leases, are ignored.
+func (kClient *K8sClient) leaderPod(flags *genericclioptions.ConfigFlags, pods []apiv1.Pod, selector PodSelector) (apiv1.Pod, bool) {
+	pods = runningPods(pods)
+	if selector.AnyReplica || len(pods) < 2 {
+		return apiv1.Pod{}, false
+	}
+	for _, pod := range pods[1:] {
+		if pod.Namespace != pods[0].Namespace {
+			return apiv1.Pod{}, false
+		}
+	}
+	leaders, err := kClient.GetLeaders(flags, pods[0].Namespace)
+	if err != nil {
+		return apiv1.Pod{}, false
+	}
+	holders := make([]apiv1.Pod, 0)
+	var held Leader
+	for _, pod := range pods {
+		for _, leader := range leaders {
+			if !leader.Expired && leader.HeldBy(pod) {
+				holders, held = append(holders, pod), leader
+				break
+			}
+		}
+	}
+	if len(holders) != 1 {
+		return apiv1.Pod{}, false
+	}
+	if util.Verbose {
+		fmt.Fprintf(os.Stderr, "Pod %v/%v is the leader, it holds %v\n", holders[0].Namespace, holders[0].Name, held)
+	}
+	return holders[0], true
+}
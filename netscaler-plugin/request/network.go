@@ -0,0 +1,181 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package request
+
+import (
+	"context"
+	"strings"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// Network describes the pod network of the cluster as seen by a NetScaler outside of it
+type Network struct {
+	// CNI is the name of the detected container network plugin, empty when none is recognized
+	CNI string
+	// Workloads are the namespace/name of the DaemonSets running the plugin
+	Workloads []string
+	// ConfigMaps are the namespace/name of the configuration of the plugin found in the cluster
+	ConfigMaps []string
+	// Resources are the resource.group of the custom resources of the plugin
+	Resources []string
+	// Routable tells whether pods get addresses of the node network, reachable without routes
+	Routable bool
+	// KubeProxyMode is the proxy mode of kube-proxy, empty when kube-proxy is not deployed
+	KubeProxyMode string
+	Nodes         []NodeNetwork
+}
+
+// NodeNetwork is the address of a node and the pod networks allocated to it
+type NodeNetwork struct {
+	Name       string
+	InternalIP string
+	PodCIDRs   []string
+}
+
+// cniSignature maps keywords found in DaemonSet names and images to a container network plugin
+type cniSignature struct {
+	name       string
+	keywords   []string
+	configMaps []string
+	resources  []string
+	routable   bool
+}
+
+// cniSignatures is evaluated in order, Canal combines Calico and Flannel and is listed first
+var cniSignatures = []cniSignature{
+	{name: "Canal", keywords: []string{"canal"}, configMaps: []string{"canal-config"},
+		resources: []string{"ippools.crd.projectcalico.org"}},
+	{name: "Calico", keywords: []string{"calico-node", "calico/node"}, configMaps: []string{"calico-config"},
+		resources: []string{"ippools.crd.projectcalico.org", "bgppeers.crd.projectcalico.org", "bgpconfigurations.crd.projectcalico.org"}},
+	{name: "Cilium", keywords: []string{"cilium"}, configMaps: []string{"cilium-config"},
+		resources: []string{"ciliumnodes.cilium.io", "ciliumbgppeeringpolicies.cilium.io"}},
+	{name: "Flannel", keywords: []string{"flannel"}, configMaps: []string{"kube-flannel-cfg"}},
+	{name: "OVN-Kubernetes", keywords: []string{"ovnkube", "ovn-kubernetes"}, configMaps: []string{"ovnkube-config"}},
+	{name: "Weave Net", keywords: []string{"weave-net", "weave-kube"}},
+	{name: "Antrea", keywords: []string{"antrea"}},
+	{name: "kube-router", keywords: []string{"kube-router"}, configMaps: []string{"kube-router-cfg"}},
+	{name: "Amazon VPC CNI", keywords: []string{"aws-node", "amazon-k8s-cni"}, configMaps: []string{"amazon-vpc-cni"}, routable: true},
+}
+
+// GetNetwork detects the container network plugin from the DaemonSets of the cluster and reads the kube-proxy
+// mode and the pod networks of the nodes
+func (kClient *K8sClient) GetNetwork(flags *genericclioptions.ConfigFlags) (Network, error) {
+	network := Network{Workloads: make([]string, 0), ConfigMaps: make([]string, 0), Resources: make([]string, 0)}
+	daemonSets, err := kClient.K8sClient.AppsV1().DaemonSets("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return network, err
+	}
+	for _, sig := range cniSignatures {
+		for _, daemonSet := range daemonSets.Items {
+			if !daemonSetMatches(daemonSet, sig.keywords) {
+				continue
+			}
+			network.CNI, network.Routable, network.Resources = sig.name, sig.routable, sig.resources
+			network.Workloads = append(network.Workloads, daemonSet.Namespace+"/"+daemonSet.Name)
+			for _, name := range sig.configMaps {
+				_, err := kClient.K8sClient.CoreV1().ConfigMaps(daemonSet.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+				if err == nil {
+					network.ConfigMaps = append(network.ConfigMaps, daemonSet.Namespace+"/"+name)
+				}
+			}
+		}
+		if network.CNI != "" {
+			break
+		}
+	}
+	for _, daemonSet := range daemonSets.Items {
+		if daemonSet.Namespace == metav1.NamespaceSystem && daemonSet.Name == constant.KubeProxy {
+			network.KubeProxyMode = kClient.kubeProxyMode()
+		}
+	}
+	nodes, err := kClient.K8sClient.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return network, err
+	}
+	for _, node := range nodes.Items {
+		nodeNetwork := NodeNetwork{Name: node.Name, PodCIDRs: node.Spec.PodCIDRs}
+		if len(nodeNetwork.PodCIDRs) == 0 && node.Spec.PodCIDR != "" {
+			nodeNetwork.PodCIDRs = []string{node.Spec.PodCIDR}
+		}
+		for _, address := range node.Status.Addresses {
+			if address.Type == apiv1.NodeInternalIP && nodeNetwork.InternalIP == "" {
+				nodeNetwork.InternalIP = address.Address
+			}
+		}
+		network.Nodes = append(network.Nodes, nodeNetwork)
+	}
+	return network, nil
+}
+
+// daemonSetMatches reports whether the name or a container image of the DaemonSet contains a keyword
+func daemonSetMatches(daemonSet appsv1.DaemonSet, keywords []string) bool {
+	names := []string{strings.ToLower(daemonSet.Name)}
+	for _, container := range daemonSet.Spec.Template.Spec.Containers {
+		names = append(names, strings.ToLower(container.Image))
+	}
+	for _, name := range names {
+		for _, keyword := range keywords {
+			if strings.Contains(name, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// kubeProxyMode reads the proxy mode of the kube-proxy configuration, iptables when it is not set
+func (kClient *K8sClient) kubeProxyMode() string {
+	configMap, err := kClient.K8sClient.CoreV1().ConfigMaps(metav1.NamespaceSystem).Get(context.TODO(), constant.KubeProxy, metav1.GetOptions{})
+	if err != nil {
+		return "unknown"
+	}
+	for _, config := range configMap.Data {
+		for _, line := range strings.Split(config, "\n") {
+			line = strings.TrimSpace(line)
+			if !strings.HasPrefix(line, "mode:") {
+				continue
+			}
+			if mode := strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "mode:")), `"'`); mode != "" {
+				return mode
+			}
+		}
+	}
+	return "iptables"
+}
+
+// RouteAddition reports whether the ingress controller adds the routes to the pod networks of the nodes on
+// NetScaler, which is enabled with --feature-node-watch true
+func RouteAddition(pod apiv1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		args := make([]string, 0)
+		for _, arg := range append(append([]string{}, container.Command...), container.Args...) {
+			args = append(args, strings.Fields(arg)...)
+		}
+		for i, arg := range args {
+			if strings.EqualFold(arg, constant.NodeWatchArg+"=true") {
+				return true
+			}
+			if arg == constant.NodeWatchArg && i+1 < len(args) && strings.EqualFold(args[i+1], "true") {
+				return true
+			}
+		}
+	}
+	return false
+}
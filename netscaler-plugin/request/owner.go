@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package request
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// maxOwnerDepth bounds the owner chain of a pod, owner references should not loop
+const maxOwnerDepth = 8
+
+// ownerKinds maps the kinds, plural and short names accepted by --owner to the kinds read through the API
+var ownerKinds = map[string]string{
+	"deployment": "Deployment", "deployments": "Deployment", "deploy": "Deployment",
+	"daemonset": "DaemonSet", "daemonsets": "DaemonSet", "ds": "DaemonSet",
+	"statefulset": "StatefulSet", "statefulsets": "StatefulSet", "sts": "StatefulSet",
+	"replicaset": "ReplicaSet", "replicasets": "ReplicaSet", "rs": "ReplicaSet",
+	"job": "Job", "jobs": "Job",
+	"cronjob": "CronJob", "cronjobs": "CronJob", "cj": "CronJob",
+}
+
+// ParseOwner splits the kind/name given with --owner. Kinds not read by the plugin, such as the Rollout of Argo
+// Rollouts, are kept as given and matched on the kind and name of the owner references.
+func ParseOwner(owner string) (string, string, error) {
+	parts := strings.SplitN(owner, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid value %v for --owner, the owner is given as kind/name such as deployment/cic", owner)
+	}
+	if kind, ok := ownerKinds[strings.ToLower(parts[0])]; ok {
+		return kind, parts[1], nil
+	}
+	return parts[0], parts[1], nil
+}
+
+// getOwner reads a workload of the namespace, nil for the kinds the plugin does not read
+func (kClient *K8sClient) getOwner(namespace string, kind string, name string) (metav1.Object, error) {
+	apps, batch := kClient.K8sClient.AppsV1(), kClient.K8sClient.BatchV1()
+	switch kind {
+	case "Deployment":
+		return apps.Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	case "DaemonSet":
+		return apps.DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	case "StatefulSet":
+		return apps.StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	case "ReplicaSet":
+		return apps.ReplicaSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	case "Job":
+		return batch.Jobs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	case "CronJob":
+		return batch.CronJobs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	}
+	return nil, nil
+}
+
+// PodOwners returns the owners of the pod, from its controller up to the top-level workload
+func (kClient *K8sClient) PodOwners(pod apiv1.Pod) []metav1.OwnerReference {
+	return kClient.ownerChain(pod, make(map[string]*metav1.OwnerReference))
+}
+
+// ownerChain follows the controller owner references of the pod through the API. A ReplicaSet which cannot be read,
+// such as in a support bundle, is attributed to the Deployment it is named after with its pod-template-hash. The
+// controllers of the owners read are kept in cache by kind/name.
+func (kClient *K8sClient) ownerChain(pod apiv1.Pod, cache map[string]*metav1.OwnerReference) []metav1.OwnerReference {
+	chain := make([]metav1.OwnerReference, 0)
+	ref := metav1.GetControllerOf(&pod)
+	for ref != nil && len(chain) < maxOwnerDepth {
+		chain = append(chain, *ref)
+		key := ref.Kind + "/" + ref.Name
+		next, ok := cache[key]
+		if !ok {
+			owner, err := kClient.getOwner(pod.Namespace, ref.Kind, ref.Name)
+			if err == nil && owner != nil {
+				next = metav1.GetControllerOfNoCopy(owner)
+			} else if hash := pod.Labels["pod-template-hash"]; err != nil && ref.Kind == "ReplicaSet" && strings.HasSuffix(ref.Name, "-"+hash) {
+				next = &metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: strings.TrimSuffix(ref.Name, "-"+hash)}
+			}
+			cache[key] = next
+		}
+		ref = next
+	}
+	return chain
+}
+
+// GetOwnedPods returns the pods of the namespace owned, directly or through other owners, by the workload of the
+// given kind and name. The owners read through the API are matched by UID, the others by kind and name.
+func (kClient *K8sClient) GetOwnedPods(flags *genericclioptions.ConfigFlags, kind string, name string) ([]apiv1.Pod, error) {
+	namespace, err := util.GetNamespace(flags)
+	if err != nil {
+		return make([]apiv1.Pod, 0), err
+	}
+	pods, err := kClient.getPods(flags)
+	if err != nil {
+		return make([]apiv1.Pod, 0), err
+	}
+	// The workload is matched by name when it cannot be read, such as a StatefulSet missing from a support bundle
+	var uid string
+	if owner, err := kClient.getOwner(namespace, kind, name); err == nil && owner != nil {
+		uid = string(owner.GetUID())
+	}
+	cache := make(map[string]*metav1.OwnerReference)
+	owned := make([]apiv1.Pod, 0)
+	for _, pod := range pods {
+		for _, ref := range kClient.ownerChain(pod, cache) {
+			if uid != "" && ref.UID != "" {
+				if string(ref.UID) == uid {
+					owned = append(owned, pod)
+					break
+				}
+			} else if strings.EqualFold(ref.Kind, kind) && ref.Name == name {
+				owned = append(owned, pod)
+				break
+			}
+		}
+	}
+	return owned, nil
+}
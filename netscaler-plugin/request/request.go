@@ -15,15 +15,19 @@ package request
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/bundle"
 	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
 
 	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	k8sclient "k8s.io/client-go/kubernetes"
@@ -31,13 +35,25 @@ import (
 
 // Struct K8sClient for common clientset and functions
 type K8sClient struct {
-	K8sClient *k8sclient.Clientset
+	K8sClient k8sclient.Interface
+	// endpointSlicesCache keeps the EndpointSlices listed per namespace for the life of the client
+	endpointSlicesCache map[string]*[]discoveryv1.EndpointSlice
 }
 
-// NewK8sClient Creates a common k8s clientset for different client types
+// NewK8sClient Creates a common k8s clientset for different client types.
+// With --from-bundle the clientset serves the objects saved in the support bundle.
 func NewK8sClient(flags *genericclioptions.ConfigFlags) (K8sClient, error) {
+	kClient := K8sClient{endpointSlicesCache: make(map[string]*[]discoveryv1.EndpointSlice)}
+	if bundle.Enabled() {
+		clientSet, err := bundle.NewClientset()
+		if err != nil {
+			kClient.K8sClient = &k8sclient.Clientset{}
+			return kClient, err
+		}
+		kClient.K8sClient = clientSet
+		return kClient, nil
+	}
 	rawConfig, err := flags.ToRESTConfig()
-	kClient := K8sClient{}
 	if err != nil {
 		kClient.K8sClient = &k8sclient.Clientset{}
 		return kClient, err
@@ -51,45 +67,48 @@ func NewK8sClient(flags *genericclioptions.ConfigFlags) (K8sClient, error) {
 	return kClient, nil
 }
 
-// ChoosePod finds a pod either by deployment or by name
-func (kClient *K8sClient) ChoosePod(flags *genericclioptions.ConfigFlags, podName string, deployment string, selector string) (apiv1.Pod, string, string, error) {
+// ChoosePod finds a pod either by name, by label or by owning workload, otherwise by discovering the only controller.
+// The owner is only parsed when neither the name nor the label is given.
+// It returns the pod with its ingress controller and NetScaler CPX containers, empty when not found.
+func (kClient *K8sClient) ChoosePod(flags *genericclioptions.ConfigFlags, selector PodSelector) (apiv1.Pod, string, string, error) {
 	var pod apiv1.Pod
 	var err error
-	if podName != "" {
-		pod, err = kClient.GetNamedPod(flags, podName)
-	} else if selector != "" {
-		pod, err = kClient.GetLabeledPod(flags, selector)
-	} else if deployment != "" {
-		pod, err = kClient.GetDeploymentPod(flags, deployment)
+	if selector.Pod != "" {
+		pod, err = kClient.GetNamedPod(flags, selector.Pod)
+	} else if selector.Label != "" {
+		pod, err = kClient.GetLabeledPod(flags, selector.Label, selector)
+	} else if kind, name, ownerErr := selector.owner(); ownerErr != nil {
+		err = ownerErr
+	} else if kind != "" {
+		pod, err = kClient.GetOwnedPod(flags, kind, name, selector)
 	} else {
-		err = errors.New("please provide either label (-l, --label), deployment (--deployment) or pod (--pod ) as a selector in the command")
+		pod, err = kClient.GetDiscoveredPod(flags, selector)
+	}
+	if err != nil {
 		return pod, "", "", err
 	}
-	cicContainer, cpxContainer := "", ""
-	if len(pod.Spec.Containers) > 0 {
-		for container := range pod.Spec.Containers {
-			for env := range pod.Spec.Containers[container].Env {
-				if pod.Spec.Containers[container].Env[env].Name == "NS_DEPLOYMENT_MODE" && pod.Spec.Containers[container].Env[env].Value == "SIDECAR" {
-					cicContainer = pod.Spec.Containers[container].Name
-				} else {
-					cpxContainer = pod.Spec.Containers[container].Name
-				}
-			}
-		}
+	cicContainer, cpxContainer, reason, err := chooseContainers(flags, pod, selector)
+	if err != nil {
+		return pod, "", "", err
+	}
+	if util.Verbose {
+		fmt.Fprintf(os.Stderr, "Using pod %v/%v, %v\n", pod.Namespace, pod.Name, reason)
 	}
-	return pod, cicContainer, cpxContainer, err
+	if issues := PodIssues(pod); len(issues) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: pod %v/%v is degraded: %v\n", pod.Namespace, pod.Name, strings.Join(issues, ", "))
+	}
+	return pod, cicContainer, cpxContainer, nil
 }
 
-// GetNamedPod finds a pod with the given name
+// GetNamedPod finds a pod with the given name, whatever its state so that an unhealthy pod can be debugged
 func (kClient *K8sClient) GetNamedPod(flags *genericclioptions.ConfigFlags, name string) (apiv1.Pod, error) {
 	allPods, err := kClient.getPods(flags)
 	if err != nil {
 
 		return apiv1.Pod{}, err
 	}
-	// Running should be constant
 	for _, pod := range allPods {
-		if pod.Name == name && pod.Status.Phase == "Running" {
+		if pod.Name == name {
 			return pod, nil
 		}
 	}
@@ -97,12 +116,13 @@ func (kClient *K8sClient) GetNamedPod(flags *genericclioptions.ConfigFlags, name
 	if err != nil {
 		return apiv1.Pod{}, err
 	}
-	return apiv1.Pod{}, fmt.Errorf("pod %v not found in namespace %v or is not in healthy state", name, namespace)
+	return apiv1.Pod{}, fmt.Errorf("pod %v not found in namespace %v", name, namespace)
 }
 
-// GetDeploymentPod finds a pod from a given deployment
-func (kClient *K8sClient) GetDeploymentPod(flags *genericclioptions.ConfigFlags, deployment string) (apiv1.Pod, error) {
-	ings, err := kClient.getDeploymentPods(flags, deployment)
+// GetOwnedPod finds a pod owned by the workload of the given kind and name, the leader of the replicas unless
+// --any-replica is set, otherwise --pick chooses between several preferred pods
+func (kClient *K8sClient) GetOwnedPod(flags *genericclioptions.ConfigFlags, kind string, name string, selector PodSelector) (apiv1.Pod, error) {
+	ings, err := kClient.GetOwnedPods(flags, kind, name)
 	if err != nil {
 
 		return apiv1.Pod{}, err
@@ -111,21 +131,24 @@ func (kClient *K8sClient) GetDeploymentPod(flags *genericclioptions.ConfigFlags,
 	if err != nil {
 		return apiv1.Pod{}, err
 	}
+	what := strings.ToLower(kind) + " " + name
 	if len(ings) == 0 {
-		return apiv1.Pod{}, fmt.Errorf("no pods for deployment %v found in namespace %v", deployment, namespace)
+		return apiv1.Pod{}, fmt.Errorf("no pods for %v found in namespace %v", what, namespace)
 	}
-	// Return the first Running pod
-	for _, pod := range ings {
-		if pod.Status.Phase == "Running" {
-			return pod, nil
-		}
+	if leader, ok := kClient.leaderPod(flags, ings, selector); ok {
+		return leader, nil
+	}
+	candidates := preferredPods(ings)
+	if len(candidates) > 0 {
+		return pickPod(candidates, selector.Pick, what)
 	}
 
-	return apiv1.Pod{}, fmt.Errorf("no pods for deployment %v found in namespace %v with healthy state", deployment, namespace)
+	return apiv1.Pod{}, fmt.Errorf("no pods for %v found in namespace %v with healthy state", what, namespace)
 }
 
-// GetLabeledPod finds a pod from a given label
-func (kClient *K8sClient) GetLabeledPod(flags *genericclioptions.ConfigFlags, label string) (apiv1.Pod, error) {
+// GetLabeledPod finds a pod from a given label, the leader of the replicas unless --any-replica is set,
+// otherwise --pick chooses between several preferred pods
+func (kClient *K8sClient) GetLabeledPod(flags *genericclioptions.ConfigFlags, label string, selector PodSelector) (apiv1.Pod, error) {
 	ings, err := kClient.getLabeledPods(flags, label)
 	if err != nil {
 		return apiv1.Pod{}, err
@@ -139,12 +162,25 @@ func (kClient *K8sClient) GetLabeledPod(flags *genericclioptions.ConfigFlags, la
 	if len(ings) == 0 {
 		return apiv1.Pod{}, fmt.Errorf("no pods for label selector %v found in namespace %v", label, namespace)
 	}
-	for _, pod := range ings {
+	if leader, ok := kClient.leaderPod(flags, ings, selector); ok {
+		return leader, nil
+	}
+	candidates := preferredPods(ings)
+	if len(candidates) > 0 {
+		return pickPod(candidates, selector.Pick, "label selector "+label)
+	}
+	return apiv1.Pod{}, fmt.Errorf("no pods for label selector %v found in namespace %v with healthy state", label, namespace)
+}
+
+// runningPods filters the pods in Running phase
+func runningPods(pods []apiv1.Pod) []apiv1.Pod {
+	running := make([]apiv1.Pod, 0, len(pods))
+	for _, pod := range pods {
 		if pod.Status.Phase == "Running" {
-			return pod, nil
+			running = append(running, pod)
 		}
 	}
-	return apiv1.Pod{}, fmt.Errorf("no pods for label selector %v found in namespace %v with healthy state", label, namespace)
+	return running
 }
 
 // GetDeployments returns an array of Deployments
@@ -171,6 +207,43 @@ func (kClient *K8sClient) GetIngressDefinitions(flags *genericclioptions.ConfigF
 	return pods.Items, nil
 }
 
+// IngressBackends returns the service backends of the default backend and of the rules of an ingress
+func IngressBackends(ingress networking.Ingress) []networking.IngressServiceBackend {
+	backends := make([]networking.IngressServiceBackend, 0)
+	if ingress.Spec.DefaultBackend != nil && ingress.Spec.DefaultBackend.Service != nil {
+		backends = append(backends, *ingress.Spec.DefaultBackend.Service)
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil {
+				backends = append(backends, *path.Backend.Service)
+			}
+		}
+	}
+	return backends
+}
+
+// BackendServices returns the backend services of the named ingress exposed on port, a port number or name
+func BackendServices(ingresses []networking.Ingress, name string, port string) []string {
+	services := make([]string, 0)
+	for _, ingress := range ingresses {
+		if ingress.Name != name {
+			continue
+		}
+		for _, backend := range IngressBackends(ingress) {
+			if backend.Port.Name == port || strconv.Itoa(int(backend.Port.Number)) == port {
+				if !util.ContainsString(services, backend.Name) {
+					services = append(services, backend.Name)
+				}
+			}
+		}
+	}
+	return services
+}
+
 // GetNumEndpoints counts the number of endpointslices adresses for the service with the given name
 func (kClient *K8sClient) GetNumEndpoints(flags *genericclioptions.ConfigFlags, namespace string, serviceName string) (*int, error) {
 	epss, err := kClient.GetEndpointSlicesByName(flags, namespace, serviceName)
@@ -211,11 +284,9 @@ func (kClient *K8sClient) GetEndpointSlicesByName(flags *genericclioptions.Confi
 	return eps, nil
 }
 
-var endpointSlicesCache = make(map[string]*[]discoveryv1.EndpointSlice)
-
 // getEndpointSlices returns the endpointSlices for the service with the given name
 func (kClient *K8sClient) getEndpointSlices(flags *genericclioptions.ConfigFlags, namespace string) ([]discoveryv1.EndpointSlice, error) {
-	cachedEndpointSlices, ok := endpointSlicesCache[namespace]
+	cachedEndpointSlices, ok := kClient.endpointSlicesCache[namespace]
 
 	if ok {
 		return *cachedEndpointSlices, nil
@@ -236,22 +307,22 @@ func (kClient *K8sClient) getEndpointSlices(flags *genericclioptions.ConfigFlags
 	}
 	endpointSlices := endpointSlicesList.Items
 
-	endpointSlicesCache[namespace] = &endpointSlices
+	kClient.endpointSlicesCache[namespace] = &endpointSlices
 	return endpointSlices, nil
 }
 
 func (kClient *K8sClient) tryAllNamespacesEndpointSlicesCache(flags *genericclioptions.ConfigFlags) {
-	_, ok := endpointSlicesCache[""]
+	_, ok := kClient.endpointSlicesCache[""]
 	if !ok {
 		_, err := kClient.getEndpointSlices(flags, "")
 		if err != nil {
-			endpointSlicesCache[""] = nil
+			kClient.endpointSlicesCache[""] = nil
 		}
 	}
 }
 
 func (kClient *K8sClient) tryFilteringEndpointSlicesFromAllNamespacesCache(flags *genericclioptions.ConfigFlags, namespace string) *[]discoveryv1.EndpointSlice {
-	allEndpointSlices := endpointSlicesCache[""]
+	allEndpointSlices := kClient.endpointSlicesCache[""]
 	if allEndpointSlices != nil {
 		endpointSlices := make([]discoveryv1.EndpointSlice, 0)
 		for _, slice := range *allEndpointSlices {
@@ -259,16 +330,24 @@ func (kClient *K8sClient) tryFilteringEndpointSlicesFromAllNamespacesCache(flags
 				endpointSlices = append(endpointSlices, slice)
 			}
 		}
-		endpointSlicesCache[namespace] = &endpointSlices
+		kClient.endpointSlicesCache[namespace] = &endpointSlices
 		return &endpointSlices
 	}
 	return nil
 }
 
-// GetServiceByName finds and returns the service definition with the given name
-func (kClient *K8sClient) GetServiceByName(flags *genericclioptions.ConfigFlags, name string, services *[]apiv1.Service) (apiv1.Service, error) {
+// GetServiceByName finds and returns the service definition with the given name, in the given namespace
+// or in the namespace of flags when empty
+func (kClient *K8sClient) GetServiceByName(flags *genericclioptions.ConfigFlags, namespace string, name string, services *[]apiv1.Service) (apiv1.Service, error) {
+	if namespace == "" {
+		ns, err := util.GetNamespace(flags)
+		if err != nil {
+			return apiv1.Service{}, err
+		}
+		namespace = ns
+	}
 	if services == nil {
-		servicesArray, err := kClient.getServices(flags)
+		servicesArray, err := kClient.GetNamespaceServices(flags, namespace)
 		if err != nil {
 			return apiv1.Service{}, err
 		}
@@ -280,10 +359,6 @@ func (kClient *K8sClient) GetServiceByName(flags *genericclioptions.ConfigFlags,
 			return svc, nil
 		}
 	}
-	namespace, err := util.GetNamespace(flags)
-	if err != nil {
-		return apiv1.Service{}, err
-	}
 	return apiv1.Service{}, fmt.Errorf("could not find service %v in namespace %v", name, namespace)
 }
 
@@ -318,34 +393,38 @@ func (kClient *K8sClient) getLabeledPods(flags *genericclioptions.ConfigFlags, l
 	return pods.Items, nil
 }
 
-// getDeploymentPods finds and returns the pods with the given deployment name
-func (kClient *K8sClient) getDeploymentPods(flags *genericclioptions.ConfigFlags, deployment string) ([]apiv1.Pod, error) {
-	pods, err := kClient.getPods(flags)
+// GetNamespaceServices returns the services of the given namespace, all namespaces if it is empty
+func (kClient *K8sClient) GetNamespaceServices(flags *genericclioptions.ConfigFlags, namespace string) ([]apiv1.Service, error) {
+	services, err := kClient.K8sClient.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
-		return make([]apiv1.Pod, 0), err
+		return make([]apiv1.Service, 0), err
 	}
+	return services.Items, nil
+}
 
-	ingressPods := make([]apiv1.Pod, 0)
-	for _, pod := range pods {
-		if util.PodInDeployment(pod, deployment) {
-			ingressPods = append(ingressPods, pod)
-		}
+// GetSecret returns the named secret, nil when it does not exist
+func (kClient *K8sClient) GetSecret(flags *genericclioptions.ConfigFlags, namespace string, name string) (*apiv1.Secret, error) {
+	secret, err := kClient.K8sClient.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
 	}
-
-	return ingressPods, nil
+	return secret, err
 }
 
-// getServices finds and returns the pods svc the given namespace name
-func (kClient *K8sClient) getServices(flags *genericclioptions.ConfigFlags) ([]apiv1.Service, error) {
-	namespace, err := util.GetNamespace(flags)
+// GetWarningEvents returns the Warning events of the given namespace, all namespaces if it is empty
+func (kClient *K8sClient) GetWarningEvents(flags *genericclioptions.ConfigFlags, namespace string) ([]apiv1.Event, error) {
+	events, err := kClient.K8sClient.CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: "type=" + apiv1.EventTypeWarning,
+	})
 	if err != nil {
-		return make([]apiv1.Service, 0), err
+		return make([]apiv1.Event, 0), err
 	}
-	services, err := kClient.K8sClient.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return make([]apiv1.Service, 0), err
+	// Field selectors are not applied to events served from a support bundle
+	warnings := make([]apiv1.Event, 0, len(events.Items))
+	for _, ev := range events.Items {
+		if ev.Type == apiv1.EventTypeWarning {
+			warnings = append(warnings, ev)
+		}
 	}
-
-	return services.Items, nil
-
+	return warnings, nil
 }
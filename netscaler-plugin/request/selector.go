@@ -0,0 +1,247 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package request
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// PodSelector holds the user inputs used to choose the ingress controller pod
+type PodSelector struct {
+	Pod        string
+	Deployment string
+	Label      string
+	Pick       string
+	// DaemonSet, StatefulSet and Owner select the pods owned by a workload, Owner is given as kind/name
+	DaemonSet   string
+	StatefulSet string
+	Owner       string
+	// Container and CPXContainer override the detected ingress controller and NetScaler CPX containers
+	Container    string
+	CPXContainer string
+	// AnyReplica disables the choice of the replica holding the leader-election lock
+	AnyReplica bool
+	// Kind of controller discovered when no selector is given, the ingress controller when empty
+	Kind string
+}
+
+// AddPodSelectorFlags registers the pod selection flags on a subcommand and returns the struct they fill
+func AddPodSelectorFlags(cmd *cobra.Command) *PodSelector {
+	selector := &PodSelector{}
+	util.AddFlagStringVarP(cmd, &selector.Pod, []byte(constant.PodFlag))
+	util.AddFlagStringVarP(cmd, &selector.Deployment, []byte(constant.DeployFlag))
+	util.AddFlagStringVarP(cmd, &selector.Label, []byte(constant.SelectorFlag))
+	util.AddFlagStringVarP(cmd, &selector.DaemonSet, []byte(constant.DaemonSetFlag))
+	util.AddFlagStringVarP(cmd, &selector.StatefulSet, []byte(constant.StatefulSetFlag))
+	util.AddFlagStringVarP(cmd, &selector.Owner, []byte(constant.OwnerFlag))
+	util.AddFlagStringVarP(cmd, &selector.Pick, []byte(constant.PickFlag))
+	util.AddFlagStringVarP(cmd, &selector.Container, []byte(constant.ContainerFlag))
+	util.AddFlagStringVarP(cmd, &selector.CPXContainer, []byte(constant.CPXContainerFlag))
+	util.AddFlagBoolVarP(cmd, &selector.AnyReplica, []byte(constant.AnyReplicaFlag))
+	return selector
+}
+
+// owner returns the kind and name of the workload given with --deployment, --daemonset, --statefulset or --owner,
+// empty when none is given
+func (selector PodSelector) owner() (string, string, error) {
+	switch {
+	case selector.Deployment != "":
+		return "Deployment", selector.Deployment, nil
+	case selector.DaemonSet != "":
+		return "DaemonSet", selector.DaemonSet, nil
+	case selector.StatefulSet != "":
+		return "StatefulSet", selector.StatefulSet, nil
+	case selector.Owner != "":
+		return ParseOwner(selector.Owner)
+	}
+	return "", "", nil
+}
+
+// pickPod chooses one of several candidate pods matched by what, using the pick strategy,
+// an interactive prompt on a terminal, or fails listing the candidates
+func pickPod(pods []apiv1.Pod, pick string, what string) (apiv1.Pod, error) {
+	if len(pods) == 1 {
+		return pods[0], nil
+	}
+	switch pick {
+	case constant.PickFirst:
+		return pods[0], nil
+	case constant.PickNewest:
+		newest := pods[0]
+		for _, pod := range pods[1:] {
+			if pod.CreationTimestamp.After(newest.CreationTimestamp.Time) {
+				newest = pod
+			}
+		}
+		return newest, nil
+	case constant.PickReady:
+		for _, pod := range pods {
+			if ready, total, _ := PodReadiness(pod); ready == total {
+				return pod, nil
+			}
+		}
+		return apiv1.Pod{}, fmt.Errorf("none of the %v pods matching %v is ready", len(pods), what)
+	case "":
+	default:
+		return apiv1.Pod{}, fmt.Errorf("unsupported value %v for --pick, supported values are %v, %v and %v", pick, constant.PickFirst, constant.PickNewest, constant.PickReady)
+	}
+	if !util.IsInteractive() {
+		candidates := make([]string, 0, len(pods))
+		for _, pod := range pods {
+			ready, total, restarts := PodReadiness(pod)
+			candidates = append(candidates, fmt.Sprintf("  %v/%v (node %v, ready %v/%v, restarts %v, age %v)", pod.Namespace, pod.Name, pod.Spec.NodeName, ready, total, restarts, podAge(pod)))
+		}
+		return apiv1.Pod{}, fmt.Errorf("%v matches %v ingress controller pods, use --pick=%v|%v|%v or a more specific selector:\n%v",
+			what, len(pods), constant.PickFirst, constant.PickNewest, constant.PickReady, strings.Join(candidates, "\n"))
+	}
+	fmt.Printf("%v matches %v ingress controller pods:\n", what, len(pods))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tNAMESPACE\tNAME\tNODE\tAGE\tRESTARTS\tREADY")
+	for i, pod := range pods {
+		ready, total, restarts := PodReadiness(pod)
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v/%v\n", i+1, pod.Namespace, pod.Name, pod.Spec.NodeName, podAge(pod), restarts, ready, total)
+	}
+	w.Flush()
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Select a pod [1-%v]: ", len(pods))
+		line, err := reader.ReadString('\n')
+		choice, convErr := strconv.Atoi(strings.TrimSpace(line))
+		if convErr == nil && choice >= 1 && choice <= len(pods) {
+			return pods[choice-1], nil
+		}
+		if err != nil {
+			return apiv1.Pod{}, fmt.Errorf("no pod selected for %v", what)
+		}
+	}
+}
+
+// PodReadiness returns the number of ready containers, containers and restarts of a pod
+func PodReadiness(pod apiv1.Pod) (int, int, int32) {
+	ready, restarts := 0, int32(0)
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			ready++
+		}
+		restarts += status.RestartCount
+	}
+	return ready, len(pod.Status.ContainerStatuses), restarts
+}
+
+// recentRestartWindow is how long a container restart makes a pod less preferred
+const recentRestartWindow = time.Hour
+
+// podReady reports whether the Ready condition of the pod is true. Pods without conditions, such as the ones of
+// a support bundle, are ready when all their containers are.
+func podReady(pod apiv1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == apiv1.PodReady {
+			return condition.Status == apiv1.ConditionTrue
+		}
+	}
+	ready, total, _ := PodReadiness(pod)
+	return ready == total
+}
+
+// recentRestart reports whether a container is crash looping or terminated within the recent restart window
+func recentRestart(status apiv1.ContainerStatus) bool {
+	if status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff" {
+		return true
+	}
+	terminated := status.LastTerminationState.Terminated
+	return terminated != nil && time.Since(terminated.FinishedAt.Time) < recentRestartWindow
+}
+
+// restartedContainers returns the number of containers of a pod which restarted recently. The status of a
+// container only records its last termination, so the restarts within the window cannot be counted.
+func restartedContainers(pod apiv1.Pod) int {
+	restarted := 0
+	for _, status := range pod.Status.ContainerStatuses {
+		if recentRestart(status) {
+			restarted++
+		}
+	}
+	return restarted
+}
+
+// preferredPods keeps the Ready pods which are not terminating with the fewest containers restarted recently.
+// When no pod is Ready, the running pods with the fewest containers restarted recently are kept.
+func preferredPods(pods []apiv1.Pod) []apiv1.Pod {
+	candidates := make([]apiv1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if pod.Status.Phase == apiv1.PodRunning && pod.DeletionTimestamp == nil && podReady(pod) {
+			candidates = append(candidates, pod)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = runningPods(pods)
+	}
+	if len(candidates) == 0 {
+		return candidates
+	}
+	fewest := restartedContainers(candidates[0])
+	for _, pod := range candidates[1:] {
+		if restarted := restartedContainers(pod); restarted < fewest {
+			fewest = restarted
+		}
+	}
+	preferred := make([]apiv1.Pod, 0, len(candidates))
+	for _, pod := range candidates {
+		if restartedContainers(pod) == fewest {
+			preferred = append(preferred, pod)
+		}
+	}
+	return preferred
+}
+
+// PodIssues returns why a pod is degraded, empty when it is running, Ready and none of its containers restarted recently
+func PodIssues(pod apiv1.Pod) []string {
+	issues := make([]string, 0)
+	if pod.DeletionTimestamp != nil {
+		issues = append(issues, "terminating")
+	}
+	if pod.Status.Phase != apiv1.PodRunning {
+		issues = append(issues, "phase "+string(pod.Status.Phase))
+	} else if !podReady(pod) {
+		ready, total, _ := PodReadiness(pod)
+		issues = append(issues, fmt.Sprintf("not ready (%v/%v containers ready)", ready, total))
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff" {
+			issues = append(issues, "container "+status.Name+" in CrashLoopBackOff")
+		} else if recentRestart(status) {
+			issues = append(issues, fmt.Sprintf("container %v restarted %v ago (%v restarts)", status.Name,
+				duration.HumanDuration(time.Since(status.LastTerminationState.Terminated.FinishedAt.Time)), status.RestartCount))
+		}
+	}
+	return issues
+}
+
+// podAge returns the age of a pod in the kubectl format
+func podAge(pod apiv1.Pod) string {
+	return duration.HumanDuration(time.Since(pod.CreationTimestamp.Time))
+}
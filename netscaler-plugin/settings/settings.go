@@ -0,0 +1,173 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package settings reads the plugin configuration file, which holds named targets and per subcommand
+// defaults, and applies them to the flags which are not given on the command line.
+package settings
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/constant"
+	"github.com/netscaler/modern-apps-toolkit/netscaler-plugin/util"
+)
+
+// TargetName is the target given with --target, the current target of the configuration file is used when it is empty
+var TargetName string
+
+// Target is a named ingress controller deployment, its fields are the values of the corresponding flags
+type Target struct {
+	Context      string `yaml:"context,omitempty"`
+	Namespace    string `yaml:"namespace,omitempty"`
+	Selector     string `yaml:"selector,omitempty"`
+	Deployment   string `yaml:"deployment,omitempty"`
+	DaemonSet    string `yaml:"daemonset,omitempty"`
+	StatefulSet  string `yaml:"statefulset,omitempty"`
+	Owner        string `yaml:"owner,omitempty"`
+	Pod          string `yaml:"pod,omitempty"`
+	Prefix       string `yaml:"prefix,omitempty"`
+	Container    string `yaml:"container,omitempty"`
+	CPXContainer string `yaml:"cpxContainer,omitempty"`
+	Output       string `yaml:"output,omitempty"`
+}
+
+// Config is the content of the plugin configuration file
+type Config struct {
+	CurrentTarget string            `yaml:"currentTarget,omitempty"`
+	Targets       map[string]Target `yaml:"targets,omitempty"`
+	// Defaults are flag values per subcommand, such as {"support": {"dir": "/var/tmp"}}
+	Defaults map[string]map[string]string `yaml:"defaults,omitempty"`
+}
+
+// selectorFlags choose the ingress controller pod, the ones of a target are ignored when one is given
+var selectorFlags = []string{"pod", "deployment", "daemonset", "statefulset", "owner", "label"}
+
+// Path returns the path of the configuration file, from the KUBECTL_NETSCALER_CONFIG environment variable
+// or kubectl-netscaler.yaml in the kubectl configuration directory
+func Path() string {
+	if path := os.Getenv(constant.ConfigFileEnv); path != "" {
+		return path
+	}
+	return filepath.Join(clientcmd.RecommendedConfigDir, constant.ConfigFileName)
+}
+
+// Load reads the configuration file, a missing file is an empty configuration
+func Load() (Config, error) {
+	config := Config{}
+	content, err := os.ReadFile(Path())
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	if err = yaml.Unmarshal(content, &config); err != nil {
+		return config, fmt.Errorf("unable to parse %v: %v", Path(), err)
+	}
+	return config, nil
+}
+
+// Marshal returns the YAML document of a configuration
+func Marshal(config Config) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), encoder.Close()
+}
+
+// Save writes the configuration file, which may hold cluster names, readable by the user only
+func Save(config Config) error {
+	content, err := Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(Path()), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(Path(), content, 0600)
+}
+
+// flagValues returns the flag values of a target
+func (target Target) flagValues() map[string]string {
+	return map[string]string{
+		"context":       target.Context,
+		"namespace":     target.Namespace,
+		"label":         target.Selector,
+		"deployment":    target.Deployment,
+		"daemonset":     target.DaemonSet,
+		"statefulset":   target.StatefulSet,
+		"owner":         target.Owner,
+		"pod":           target.Pod,
+		"prefix":        target.Prefix,
+		"container":     target.Container,
+		"cpx-container": target.CPXContainer,
+		"output":        target.Output,
+	}
+}
+
+// Apply sets the flags of cmd which are not given on the command line from the selected target, otherwise from
+// the defaults of the subcommand. The CmdFlag defaults of constant remain the fallback.
+func Apply(cmd *cobra.Command) error {
+	config, err := Load()
+	if err != nil {
+		return err
+	}
+	values := make(map[string]string)
+	for flag, value := range config.Defaults[cmd.Name()] {
+		values[flag] = value
+	}
+	name := TargetName
+	if name == "" {
+		name = config.CurrentTarget
+	}
+	if name != "" {
+		target, ok := config.Targets[name]
+		if !ok {
+			return fmt.Errorf("target %v not found in %v", name, Path())
+		}
+		selected := false
+		for _, flag := range selectorFlags {
+			selected = selected || cmd.Flags().Changed(flag)
+		}
+		for flag, value := range target.flagValues() {
+			if value == "" || (selected && util.ContainsString(selectorFlags, flag)) {
+				continue
+			}
+			values[flag] = value
+		}
+	}
+	// The context of the target does not apply when the command runs against several contexts
+	if cmd.Flags().Changed("contexts") || cmd.Flags().Changed("all-contexts") {
+		delete(values, "context")
+	}
+	for flag, value := range values {
+		if cmd.Flags().Lookup(flag) == nil || cmd.Flags().Changed(flag) {
+			continue
+		}
+		if err = cmd.Flags().Set(flag, value); err != nil {
+			return fmt.Errorf("invalid value %q of %v in %v: %v", value, flag, Path(), err)
+		}
+	}
+	return nil
+}
@@ -15,21 +15,26 @@ package util
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"regexp"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
-	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
 var versionRegex = regexp.MustCompile(`(\d)+\.(\d)+\.(\d)+.*`)
 
+// Verbose is set with the global --verbose option, the choices made on behalf of the user are then explained
+var Verbose bool
+
+// promptsDisabled is set when prompts cannot be answered, such as when several clusters are queried concurrently
+var promptsDisabled bool
+
 // Struct CmdFlag for templating cobra command types
 type CmdFlag struct {
 	/*************************WARNING*************************
@@ -40,6 +45,7 @@ type CmdFlag struct {
 	CmdSName    string `json:"CmdSName,omitempty"`
 	DefValueStr string `json:"DefValueStr,omitempty"`
 	DefValueB   bool   `json:"DefValueB,omitempty"`
+	DefValueInt int    `json:"DefValueInt,omitempty"`
 	CmdDesc     string `json:"CmdDesc,omitempty"`
 }
 
@@ -47,10 +53,29 @@ type CmdFlag struct {
 // This function returns the command line arguments of string type
 func AddFlagStringP(cmd *cobra.Command, flagDetails []byte) *string {
 	cmdStr := ""
+	AddFlagStringVarP(cmd, &cmdStr, flagDetails)
+	return &cmdStr
+}
+
+// AddFlag for String stored in an existing variable. Receives cobra references, the variable and Json byte array
+func AddFlagStringVarP(cmd *cobra.Command, cmdStr *string, flagDetails []byte) {
 	var cmdFlag CmdFlag
 	json.Unmarshal(flagDetails, &cmdFlag)
-	cmd.Flags().StringVarP(&cmdStr, cmdFlag.CmdLName, cmdFlag.CmdSName, cmdFlag.DefValueStr, cmdFlag.CmdDesc)
-	return &cmdStr
+	cmd.Flags().StringVarP(cmdStr, cmdFlag.CmdLName, cmdFlag.CmdSName, cmdFlag.DefValueStr, cmdFlag.CmdDesc)
+}
+
+// AddPersistentFlagStringVarP adds a String flag inherited by all subcommands. Receives cobra references, the variable and Json byte array
+func AddPersistentFlagStringVarP(cmd *cobra.Command, cmdStr *string, flagDetails []byte) {
+	var cmdFlag CmdFlag
+	json.Unmarshal(flagDetails, &cmdFlag)
+	cmd.PersistentFlags().StringVarP(cmdStr, cmdFlag.CmdLName, cmdFlag.CmdSName, cmdFlag.DefValueStr, cmdFlag.CmdDesc)
+}
+
+// AddPersistentFlagBoolVarP adds a Boolean flag inherited by all subcommands. Receives cobra references, the variable and Json byte array
+func AddPersistentFlagBoolVarP(cmd *cobra.Command, cmdBool *bool, flagDetails []byte) {
+	var cmdFlag CmdFlag
+	json.Unmarshal(flagDetails, &cmdFlag)
+	cmd.PersistentFlags().BoolVarP(cmdBool, cmdFlag.CmdLName, cmdFlag.CmdSName, cmdFlag.DefValueB, cmdFlag.CmdDesc)
 }
 
 // AddFlag for Boolean. Receives cobra references and Json byte array
@@ -63,6 +88,23 @@ func AddFlagBoolP(cmd *cobra.Command, flagDetails []byte) *bool {
 	return &cmdBool
 }
 
+// AddFlagBoolVarP adds a Boolean flag stored in an existing variable. Receives cobra references, the variable and Json byte array
+func AddFlagBoolVarP(cmd *cobra.Command, cmdBool *bool, flagDetails []byte) {
+	var cmdFlag CmdFlag
+	json.Unmarshal(flagDetails, &cmdFlag)
+	cmd.Flags().BoolVarP(cmdBool, cmdFlag.CmdLName, cmdFlag.CmdSName, cmdFlag.DefValueB, cmdFlag.CmdDesc)
+}
+
+// AddFlag for Integer. Receives cobra references and Json byte array
+// This function returns the command line arguments of int type
+func AddFlagIntP(cmd *cobra.Command, flagDetails []byte) *int {
+	cmdInt := 0
+	var cmdFlag CmdFlag
+	json.Unmarshal(flagDetails, &cmdFlag)
+	cmd.Flags().IntVarP(&cmdInt, cmdFlag.CmdLName, cmdFlag.CmdSName, cmdFlag.DefValueInt, cmdFlag.CmdDesc)
+	return &cmdInt
+}
+
 // PrintError receives an error value and prints it if it exists
 func PrintError(e error) {
 	if e != nil {
@@ -72,15 +114,39 @@ func PrintError(e error) {
 
 // CmdErrorHandling receives an error value and gracefully exits in case of exitError
 func CmdErrorHandling(err error) {
-	if _, ok := err.(*exec.ExitError); ok {
-		fmt.Println("error while executing commands in the container")
-		os.Exit(1)
-	} else if err != nil {
+	if err = CmdError(err); err != nil {
 		fmt.Println(err.Error())
 		os.Exit(1)
 	}
 }
 
+// CmdError returns the error to report for a command run in a container, without exiting
+func CmdError(err error) error {
+	if _, ok := err.(*exec.ExitError); ok {
+		return errors.New("error while executing commands in the container")
+	}
+	return err
+}
+
+// DisablePrompts makes IsInteractive report false
+func DisablePrompts() {
+	promptsDisabled = true
+}
+
+// IsInteractive reports whether both stdin and stdout are attached to a terminal
+func IsInteractive() bool {
+	if promptsDisabled {
+		return false
+	}
+	for _, file := range []*os.File{os.Stdin, os.Stdout} {
+		info, err := file.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // Indicator function receives a channel and keeps printing dots every second to notify background process
 func Indicator(shutdownCh <-chan struct{}) {
 	ticker := time.NewTicker(time.Second * 2)
@@ -137,19 +203,10 @@ func isVersionLessThan(a, b string) bool {
 	return aPatch < bPatch
 }
 
-// PodInDeployment returns whether a pod is part of a deployment with the given name
-// a pod is considered to be in {deployment} if it is owned by a replicaset with a name of format {deployment}-otherchars
-func PodInDeployment(pod apiv1.Pod, deployment string) bool {
-	for _, owner := range pod.OwnerReferences {
-		if owner.Controller == nil || !*owner.Controller || owner.Kind != "ReplicaSet" {
-			continue
-		}
-
-		if strings.Count(owner.Name, "-") != strings.Count(deployment, "-")+1 {
-			continue
-		}
-
-		if strings.HasPrefix(owner.Name, deployment+"-") {
+// ContainsString reports whether value is one of items
+func ContainsString(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
 			return true
 		}
 	}